@@ -3,13 +3,72 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
 
 	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
+// Common OpenAI model names. Agent.Model accepts any string, so newer or
+// fine-tuned models can be used without a constant.
+const (
+	// ModelGPT4o is the GPT-4o model
+	ModelGPT4o = "gpt-4o"
+
+	// ModelGPT4oMini is the GPT-4o mini model
+	ModelGPT4oMini = "gpt-4o-mini"
+
+	// ModelGPT41 is the GPT-4.1 model
+	ModelGPT41 = "gpt-4.1"
+
+	// ModelGPT41Mini is the GPT-4.1 mini model
+	ModelGPT41Mini = "gpt-4.1-mini"
+
+	// ModelGPT41Nano is the GPT-4.1 nano model
+	ModelGPT41Nano = "gpt-4.1-nano"
+
+	// ModelO1 is the o1 reasoning model
+	ModelO1 = "o1"
+
+	// ModelO3 is the o3 reasoning model
+	ModelO3 = "o3"
+
+	// ModelO3Mini is the o3-mini reasoning model
+	ModelO3Mini = "o3-mini"
+
+	// ModelO4Mini is the o4-mini reasoning model
+	ModelO4Mini = "o4-mini"
+
+	// ModelGPT35Turbo is the GPT-3.5 Turbo model
+	ModelGPT35Turbo = "gpt-3.5-turbo"
+)
+
+const (
+	// MinLogitBias is the lowest accepted logit bias, effectively banning a token
+	MinLogitBias = -100
+
+	// MaxLogitBias is the highest accepted logit bias, effectively forcing a token
+	MaxLogitBias = 100
+)
+
+// Tool choices for Agent.ToolChoice and RunConfig.ToolChoice. Any other
+// value is the name of the tool the model must call.
+const (
+	// ToolChoiceAuto lets the model decide whether to call tools
+	ToolChoiceAuto = "auto"
+
+	// ToolChoiceNone prevents the model from calling tools
+	ToolChoiceNone = "none"
+
+	// ToolChoiceRequired makes the model call at least one tool
+	ToolChoiceRequired = "required"
+)
+
 const (
 	// DefaultModel is the default OpenAI model used for agents
-	DefaultModel = "gpt-4o"
+	DefaultModel = ModelGPT4o
 
 	// DefaultInstructions is the default instruction for agents
 	DefaultInstructions = "You are a helpful agent."
@@ -30,10 +89,31 @@ type Agent struct {
 	// Function signature: func(context.Context) string or func() string.
 	Instructions any
 
+	// Examples are few-shot exchanges sent on every turn after the system
+	// message and before the conversation. They are not part of the run's
+	// history, so they never show up in Result.Messages.
+	Examples []Example
+
 	// Tools is a list of tools available to the agent.
 	Tools []Tool
 
+	// Handoffs are the agents this agent can transfer the conversation to.
+	// Each is offered to the model as a tool after Tools.
+	Handoffs []*Handoff
+
+	// ToolChoice controls whether and which tool the model calls: one of
+	// the ToolChoice constants or the name of one of the agent's tools.
+	// ToolChoiceRequired and tool names only apply until the agent has
+	// called a tool, so the model can then answer. Empty means auto.
+	// Can be overridden by RunConfig.
+	ToolChoice string
+
 	// ParallelToolCalls determines if tools can be called in parallel.
+	// When the model requests several tools at once, their callbacks then
+	// run concurrently, each with its own copy of the context variables
+	// whose changes are merged back in call order; notes appended to the
+	// scratchpad are all kept. Values stored in the variables are shared,
+	// so callbacks must not mutate them in place.
 	// Can be overridden by RunConfig.
 	ParallelToolCalls bool
 
@@ -45,15 +125,77 @@ type Agent struct {
 	// If nil, uses model default
 	MaxTokens *int
 
+	// TopP enables nucleus sampling over the tokens making up this
+	// probability mass (0.0 to 1.0)
+	// If nil, uses model default
+	TopP *float64
+
+	// FrequencyPenalty penalizes tokens by how often they already appeared
+	// (-2.0 to 2.0)
+	// If nil, uses model default
+	FrequencyPenalty *float64
+
+	// PresencePenalty penalizes tokens that already appeared at all
+	// (-2.0 to 2.0)
+	// If nil, uses model default
+	PresencePenalty *float64
+
+	// Seed makes sampling deterministic on a best-effort basis
+	// If nil, sampling is not seeded
+	Seed *int64
+
+	// Stop lists up to 4 sequences at which the model stops generating,
+	// e.g. a delimiter ending a section. The sequence is not included in
+	// the output. If empty, no stop sequences are sent
+	Stop []string
+
+	// LogitBias maps token IDs to a bias between MinLogitBias and MaxLogitBias
+	// that makes the token more or less likely. Can be extended by RunConfig.
+	LogitBias map[int]int
+
 	// ResponseFormat defines the structure of the response (for structured outputs)
 	// If nil, responses will be unstructured text
 	ResponseFormat *jsonschema.ResponseFormat
 
+	// Router, if set, picks the agent that handles the run from the latest
+	// user message before the first LLM call, e.g. with an intent classifier.
+	// Returning nil keeps this agent; the chosen agent's own Router is
+	// consulted in turn.
+	Router func(ctx context.Context, input string) (*Agent, error)
+
+	// ConversationGuardrails check the full history before each of this
+	// agent's turns, after those in RunConfig.ConversationGuardrails.
+	ConversationGuardrails []ConversationGuardrail
+
+	// OutputGuardrails check the final output when this agent produced it,
+	// after those in RunConfig.OutputGuardrails.
+	OutputGuardrails []OutputGuardrail
+
 	// OnBeforeRun is called before the agent starts execution
 	OnBeforeRun LifecycleFunc
 
 	// OnAfterRun is called after the agent completes execution
 	OnAfterRun LifecycleFunc
+
+	// OnToolStart is called before each of the agent's tool calls with the
+	// tool name and raw JSON arguments. Returning an error skips the tool;
+	// the error is recorded on the ToolCall and reported to the model.
+	OnToolStart func(ctx context.Context, toolName string, args string) error
+
+	// OnToolEnd is called after each tool call the agent ran, with the
+	// callback's result and error. Returning an error turns the call into a
+	// failed one, recording that error instead of the result. With
+	// ParallelToolCalls it may be called concurrently.
+	OnToolEnd func(ctx context.Context, toolName string, result any, err error) error
+}
+
+// Example is a few-shot user/assistant exchange shown to the model.
+type Example struct {
+	// User is the example user message
+	User string
+
+	// Assistant is the reply the model should imitate
+	Assistant string
 }
 
 // NewAgent creates a new Agent with default values.
@@ -66,6 +208,73 @@ func NewAgent(name string) *Agent {
 	}
 }
 
+// WithModel sets the model and returns the agent for chaining.
+// Any model name is accepted; see the Model constants for common ones.
+func (a *Agent) WithModel(model string) *Agent {
+	a.Model = model
+	return a
+}
+
+// ToolSchemas returns the tool definitions exactly as the runner sends them
+// to the model, including defaults such as the empty parameter schema.
+func (a *Agent) ToolSchemas() []openai.ChatCompletionToolParam {
+	tools := a.allTools()
+	if len(tools) == 0 {
+		return nil
+	}
+	params := make([]openai.ChatCompletionToolParam, 0, len(tools))
+	for _, t := range tools {
+		params = append(params, t.ToParam())
+	}
+	return params
+}
+
+// allTools returns the agent's tools followed by the synthetic tools of its
+// handoffs, skipping handoffs without an agent.
+func (a *Agent) allTools() []Tool {
+	if len(a.Handoffs) == 0 {
+		return a.Tools
+	}
+	tools := make([]Tool, 0, len(a.Tools)+len(a.Handoffs))
+	tools = append(tools, a.Tools...)
+	for _, h := range a.Handoffs {
+		if h != nil && h.Agent != nil {
+			tools = append(tools, h.tool())
+		}
+	}
+	return tools
+}
+
+// toolMap returns the agent's tools, including those of its handoffs, by
+// name. It fails on handoffs without an agent and on duplicate names, which
+// would otherwise shadow each other.
+func (a *Agent) toolMap() (map[string]Tool, error) {
+	for i, h := range a.Handoffs {
+		if h == nil || h.Agent == nil {
+			return nil, fmt.Errorf("%w: handoff %d of agent %s has no agent", ErrInvalidHandoff, i, a.Name)
+		}
+	}
+	tools := a.allTools()
+	toolMap := make(map[string]Tool, len(tools))
+	for _, t := range tools {
+		if _, found := toolMap[t.Name]; found {
+			return nil, fmt.Errorf("%w: agent %s has more than one tool named %q", ErrDuplicateTool, a.Name, t.Name)
+		}
+		toolMap[t.Name] = t
+	}
+	return toolMap, nil
+}
+
+// ToolSchemasJSON returns ToolSchemas as indented JSON, e.g. for debugging
+// or for rendering a tool palette.
+func (a *Agent) ToolSchemasJSON() ([]byte, error) {
+	schemas := a.ToolSchemas()
+	if schemas == nil {
+		schemas = []openai.ChatCompletionToolParam{}
+	}
+	return json.MarshalIndent(schemas, "", "  ")
+}
+
 // GetInstructions returns the instructions for the agent, resolving functions if necessary.
 func (a *Agent) GetInstructions(ctx context.Context) string {
 	switch v := a.Instructions.(type) {
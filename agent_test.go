@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 )
 
@@ -115,3 +116,64 @@ func TestLifecycleHooks(t *testing.T) {
 		t.Error("OnAfterRun was not called")
 	}
 }
+
+func TestWithModel(t *testing.T) {
+	agent := NewAgent("TestAgent").WithModel(ModelGPT4oMini)
+
+	if agent.Model != "gpt-4o-mini" {
+		t.Errorf("expected Model=gpt-4o-mini, got %s", agent.Model)
+	}
+
+	// Arbitrary model names are still accepted
+	agent.WithModel("ft:gpt-4o-mini:my-org::abc123")
+	if agent.Model != "ft:gpt-4o-mini:my-org::abc123" {
+		t.Errorf("expected custom model, got %s", agent.Model)
+	}
+}
+
+func TestToolSchemas(t *testing.T) {
+	agent := NewAgent("TestAgent")
+	if agent.ToolSchemas() != nil {
+		t.Error("expected nil schemas for an agent without tools")
+	}
+	if data, err := agent.ToolSchemasJSON(); err != nil || string(data) != "[]" {
+		t.Errorf("expected empty JSON array, got %s (err %v)", data, err)
+	}
+
+	noop := func(_ map[string]any, _ ContextVariables) (any, error) { return nil, nil }
+	agent.Tools = []Tool{
+		FunctionTool("lookup", "Look up a record", nil, noop),
+		FunctionTool("search", "Search", map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"q": map[string]any{"type": "string"}},
+		}, noop),
+	}
+
+	data, err := agent.ToolSchemasJSON()
+	if err != nil {
+		t.Fatalf("ToolSchemasJSON failed: %v", err)
+	}
+
+	var got []struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name       string         `json:"name"`
+			Parameters map[string]any `json:"parameters"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 schemas, got %d", len(got))
+	}
+	if got[0].Type != "function" || got[0].Function.Name != "lookup" {
+		t.Errorf("unexpected first schema: %+v", got[0])
+	}
+	if got[0].Function.Parameters["type"] != "object" {
+		t.Errorf("expected nil parameters to default to an object schema, got %v", got[0].Function.Parameters)
+	}
+	if _, ok := got[1].Function.Parameters["properties"].(map[string]any)["q"]; !ok {
+		t.Errorf("expected custom parameters to be kept, got %v", got[1].Function.Parameters)
+	}
+}
@@ -0,0 +1,90 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ArgString returns the string argument stored under key.
+func ArgString(args map[string]any, key string) (string, error) {
+	v, ok := args[key]
+	if !ok {
+		return "", fmt.Errorf("missing argument %q", key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("argument %q is %T, not a string", key, v)
+	}
+	return s, nil
+}
+
+// ArgBool returns the boolean argument stored under key.
+func ArgBool(args map[string]any, key string) (bool, error) {
+	v, ok := args[key]
+	if !ok {
+		return false, fmt.Errorf("missing argument %q", key)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("argument %q is %T, not a boolean", key, v)
+	}
+	return b, nil
+}
+
+// ArgFloat returns the numeric argument stored under key as a float64.
+// It accepts both float64 and json.Number values.
+func ArgFloat(args map[string]any, key string) (float64, error) {
+	v, ok := args[key]
+	if !ok {
+		return 0, fmt.Errorf("missing argument %q", key)
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("argument %q is not a number: %w", key, err)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("argument %q is %T, not a number", key, v)
+	}
+}
+
+// ArgInt returns the numeric argument stored under key as an int.
+// It accepts json.Number and integral float64 values, and rejects
+// numbers with a fractional part.
+func ArgInt(args map[string]any, key string) (int, error) {
+	v, ok := args[key]
+	if !ok {
+		return 0, fmt.Errorf("missing argument %q", key)
+	}
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int64:
+		return int(n), nil
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("argument %q is %v, not an integer", key, n)
+		}
+		return int(n), nil
+	case json.Number:
+		i, err := strconv.Atoi(n.String())
+		if err != nil {
+			return 0, fmt.Errorf("argument %q is %s, not an integer", key, n)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("argument %q is %T, not an integer", key, v)
+	}
+}
@@ -0,0 +1,74 @@
+package agents
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestArgInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    int
+		wantErr bool
+	}{
+		{name: "json.Number", value: json.Number("42"), want: 42},
+		{name: "integral float64", value: float64(42), want: 42},
+		{name: "int", value: 7, want: 7},
+		{name: "fractional float64", value: 4.5, wantErr: true},
+		{name: "fractional json.Number", value: json.Number("4.5"), wantErr: true},
+		{name: "string", value: "42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ArgInt(map[string]any{"count": tt.value}, "count")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestArgFloat(t *testing.T) {
+	args := map[string]any{"a": json.Number("1.5"), "b": 2.5, "c": "x"}
+
+	if got, err := ArgFloat(args, "a"); err != nil || got != 1.5 {
+		t.Errorf("expected 1.5, got %v (err %v)", got, err)
+	}
+	if got, err := ArgFloat(args, "b"); err != nil || got != 2.5 {
+		t.Errorf("expected 2.5, got %v (err %v)", got, err)
+	}
+	if _, err := ArgFloat(args, "c"); err == nil {
+		t.Error("expected error for string value")
+	}
+	if _, err := ArgFloat(args, "missing"); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
+
+func TestArgStringAndBool(t *testing.T) {
+	args := map[string]any{"name": "Paris", "ok": true}
+
+	if got, err := ArgString(args, "name"); err != nil || got != "Paris" {
+		t.Errorf("expected Paris, got %q (err %v)", got, err)
+	}
+	if _, err := ArgString(args, "ok"); err == nil {
+		t.Error("expected error for non-string value")
+	}
+	if got, err := ArgBool(args, "ok"); err != nil || !got {
+		t.Errorf("expected true, got %v (err %v)", got, err)
+	}
+	if _, err := ArgBool(args, "missing"); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
@@ -0,0 +1,126 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// azureDeploymentRoutes are the API routes Azure OpenAI serves per
+// deployment; other routes, such as files, stay at the resource level
+var azureDeploymentRoutes = []string{"chat/completions", "completions", "embeddings"}
+
+// AzureConfig describes an Azure OpenAI resource for NewAzureClient.
+type AzureConfig struct {
+	// Endpoint is the resource endpoint, e.g.
+	// "https://my-resource.openai.azure.com"
+	Endpoint string
+
+	// APIVersion is the Azure OpenAI API version, e.g. "2024-10-21"
+	APIVersion string
+
+	// APIKey authenticates with the resource. RunConfig.APIKey overrides it
+	// per run, as it does for OpenAI.
+	APIKey string
+
+	// Deployments maps model names, as used in Agent.Model, to the names of
+	// the deployments serving them. Models without an entry are used as
+	// deployment names directly.
+	Deployments map[string]string
+}
+
+// NewAzureClient returns a client for an Azure OpenAI resource, to be passed
+// to NewRunner. Azure addresses models by deployment, so requests for an
+// agent's model are sent to the deployment configured for it, and the key is
+// sent in the api-key header Azure expects. Further options, such as
+// option.WithMaxRetries, are applied after the Azure configuration.
+func NewAzureClient(config AzureConfig, opts ...option.RequestOption) (*openai.Client, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("azure: endpoint is required")
+	}
+	if config.APIVersion == "" {
+		return nil, errors.New("azure: API version is required")
+	}
+
+	base := strings.TrimSuffix(config.Endpoint, "/") + "/openai/"
+	azureOpts := []option.RequestOption{
+		option.WithBaseURL(base),
+		option.WithQueryAdd("api-version", config.APIVersion),
+		option.WithAPIKey(config.APIKey),
+		option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			if err := routeToDeployment(req, config.Deployments); err != nil {
+				return nil, err
+			}
+			if key, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+				req.Header.Del("Authorization")
+				req.Header.Set("api-key", key)
+			}
+			return next(req)
+		}),
+	}
+	client := openai.NewClient(append(azureOpts, opts...)...)
+	return &client, nil
+}
+
+// routeToDeployment rewrites a request for a deployment route, e.g.
+// /openai/chat/completions, to the deployment serving the model named in
+// its body, e.g. /openai/deployments/gpt-4o-prod/chat/completions.
+func routeToDeployment(req *http.Request, deployments map[string]string) error {
+	prefix, route, ok := strings.Cut(req.URL.Path, "/openai/")
+	if !ok || req.Body == nil || !slices.Contains(azureDeploymentRoutes, route) {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	var params struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(body, &params); err != nil || params.Model == "" {
+		return nil
+	}
+	deployment := params.Model
+	if d, ok := deployments[params.Model]; ok {
+		deployment = d
+	}
+	req.URL.Path = prefix + "/openai/deployments/" + deployment + "/" + route
+	req.URL.RawPath = ""
+	return nil
+}
+
+// NewAzureChatClient wraps chat, the chat completions of a client for an
+// Azure OpenAI resource set up some other way than NewAzureClient, such as
+// with the openai-go azure package, so that requests name the deployment
+// serving the agent's model instead of the model. Models without an entry
+// in deployments are sent unchanged. Use it as Runner.Chat.
+func NewAzureChatClient(chat ChatClient, deployments map[string]string) ChatClient {
+	return &azureChatClient{chat: chat, deployments: deployments}
+}
+
+type azureChatClient struct {
+	chat        ChatClient
+	deployments map[string]string
+}
+
+func (c *azureChatClient) New(ctx context.Context, body openai.ChatCompletionNewParams, opts ...option.RequestOption) (*openai.ChatCompletion, error) {
+	if deployment, ok := c.deployments[body.Model]; ok {
+		body.Model = deployment
+	}
+	return c.chat.New(ctx, body, opts...)
+}
@@ -0,0 +1,135 @@
+package agents
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+func TestNewAzureClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		runKey   string
+		wantPath string
+		wantKey  string
+	}{
+		{
+			name:     "mapped deployment",
+			model:    "gpt-4o",
+			wantPath: "/openai/deployments/gpt-4o-prod/chat/completions",
+			wantKey:  "azure-key",
+		},
+		{
+			name:     "model as deployment",
+			model:    "gpt-4o-mini",
+			wantPath: "/openai/deployments/gpt-4o-mini/chat/completions",
+			wantKey:  "azure-key",
+		},
+		{
+			name:     "per-run key",
+			model:    "gpt-4o",
+			runKey:   "run-key",
+			wantPath: "/openai/deployments/gpt-4o-prod/chat/completions",
+			wantKey:  "run-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			var body []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Clone(context.Background())
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, textCompletion("hi from azure"))
+			}))
+			t.Cleanup(srv.Close)
+
+			client, err := NewAzureClient(AzureConfig{
+				Endpoint:    srv.URL + "/",
+				APIVersion:  "2024-10-21",
+				APIKey:      "azure-key",
+				Deployments: map[string]string{"gpt-4o": "gpt-4o-prod"},
+			}, option.WithMaxRetries(0))
+			if err != nil {
+				t.Fatalf("NewAzureClient failed: %v", err)
+			}
+
+			agent := NewAgent("Assistant")
+			agent.Model = tt.model
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+			result, err := NewRunner(client).Run(context.Background(), agent, messages, nil, &RunConfig{APIKey: tt.runKey})
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if result.FinalOutput != "hi from azure" {
+				t.Errorf("unexpected output %q", result.FinalOutput)
+			}
+			if got.URL.Path != tt.wantPath {
+				t.Errorf("expected path %s, got %s", tt.wantPath, got.URL.Path)
+			}
+			if v := got.URL.Query().Get("api-version"); v != "2024-10-21" {
+				t.Errorf("expected api-version 2024-10-21, got %q", v)
+			}
+			if key := got.Header.Get("api-key"); key != tt.wantKey {
+				t.Errorf("expected api-key %q, got %q", tt.wantKey, key)
+			}
+			if auth := got.Header.Get("Authorization"); auth != "" {
+				t.Errorf("expected no Authorization header, got %q", auth)
+			}
+			if len(body) == 0 {
+				t.Error("expected the request body to be forwarded")
+			}
+		})
+	}
+}
+
+func TestNewAzureClient_Validation(t *testing.T) {
+	if _, err := NewAzureClient(AzureConfig{APIVersion: "2024-10-21"}); err == nil {
+		t.Error("expected an error without an endpoint")
+	}
+	if _, err := NewAzureClient(AzureConfig{Endpoint: "https://example.openai.azure.com"}); err == nil {
+		t.Error("expected an error without an API version")
+	}
+}
+
+func TestNewAzureChatClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		wantModel string
+	}{
+		{name: "mapped deployment", model: "gpt-4o", wantModel: "gpt-4o-prod"},
+		{name: "model as deployment", model: "gpt-4o-mini", wantModel: "gpt-4o-mini"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat := &fakeChat{responses: []string{textCompletion("hi from azure")}}
+			runner := NewRunnerWithClient(NewAzureChatClient(chat, map[string]string{"gpt-4o": "gpt-4o-prod"}))
+
+			agent := NewAgent("Assistant")
+			agent.Model = tt.model
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+			result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if result.FinalOutput != "hi from azure" {
+				t.Errorf("unexpected output %q", result.FinalOutput)
+			}
+			if got := chat.Requests()[0].Model; got != tt.wantModel {
+				t.Errorf("expected model %s, got %s", tt.wantModel, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package agents
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// jsonNumber matches the JSON number grammar, which unlike strconv.ParseFloat
+// rejects hex floats, underscores, leading '+' and spelled-out infinities.
+var jsonNumber = regexp.MustCompile(`^-?(?:0|[1-9][0-9]*)(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?$`)
+
+// ArgumentCoercion normalizes tool arguments before the callback runs,
+// smoothing over formatting drift in the arguments models produce.
+type ArgumentCoercion struct {
+	// NormalizeKeys renames argument keys that match a declared parameter
+	// apart from case and snake/camel/kebab style (e.g. "userId" for
+	// "user_id") to the declared name. Keys that already match are kept.
+	NormalizeKeys bool
+	// CoerceNumbers converts string values of parameters declared as
+	// "number" or "integer" to numbers. Strings that are not JSON numbers,
+	// or overflow a float64, are left untouched for the callback to reject.
+	CoerceNumbers bool
+	// Defaults are applied to arguments that are missing or null. Map and
+	// slice values are copied for every call, so callbacks may modify them.
+	Defaults map[string]any
+}
+
+// apply rewrites args in place according to the coercion settings. The
+// declared parameters are read from the tool's JSON schema properties.
+func (c *ArgumentCoercion) apply(args map[string]any, params map[string]any, useNumber bool) {
+	props, _ := params["properties"].(map[string]any)
+
+	if c.NormalizeKeys && len(props) > 0 {
+		normalizeArgKeys(args, props)
+	}
+
+	if c.CoerceNumbers {
+		for name, prop := range props {
+			s, ok := args[name].(string)
+			if !ok || !isNumericSchema(prop) {
+				continue
+			}
+			s = strings.TrimSpace(s)
+			if !jsonNumber.MatchString(s) {
+				continue
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				continue
+			}
+			if useNumber {
+				args[name] = json.Number(s)
+			} else {
+				args[name] = f
+			}
+		}
+	}
+
+	for name, v := range c.Defaults {
+		if args[name] == nil {
+			args[name] = v
+			if v != nil {
+				args[name] = copyValue(reflect.ValueOf(v)).Interface()
+			}
+		}
+	}
+}
+
+// copyValue returns a deep copy of the maps and slices in v. Other values,
+// including pointers, are returned as is.
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			c.Index(i).Set(copyValue(v.Index(i)))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem()))
+		return c
+	}
+	return v
+}
+
+// normalizeArgKeys renames keys of args that differ from a declared
+// property only in style to the property name.
+func normalizeArgKeys(args map[string]any, props map[string]any) {
+	declared := make(map[string]string, len(props))
+	for name := range props {
+		declared[canonicalArgKey(name)] = name
+	}
+
+	for key, v := range args {
+		if _, ok := props[key]; ok {
+			continue
+		}
+		name, ok := declared[canonicalArgKey(key)]
+		if !ok {
+			continue
+		}
+		// Never overwrite an argument the model supplied under the right name
+		if _, exists := args[name]; exists {
+			continue
+		}
+		delete(args, key)
+		args[name] = v
+	}
+}
+
+// canonicalArgKey lowercases key and drops word separators, so that
+// "user_id", "userId", "UserID" and "user-id" compare equal.
+func canonicalArgKey(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		if r == '_' || r == '-' || r == ' ' {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isNumericSchema reports whether a property schema declares a number or
+// integer type, either alone or in a type list such as ["integer", "null"].
+func isNumericSchema(prop any) bool {
+	schema, ok := prop.(map[string]any)
+	if !ok {
+		return false
+	}
+	switch t := schema["type"].(type) {
+	case string:
+		return t == "number" || t == "integer"
+	case []any:
+		for _, v := range t {
+			if v == "number" || v == "integer" {
+				return true
+			}
+		}
+	case []string:
+		for _, v := range t {
+			if v == "number" || v == "integer" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package agents
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestArgumentCoercion(t *testing.T) {
+	params := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"user_id": map[string]any{"type": "integer"},
+			"limit":   map[string]any{"type": []any{"number", "null"}},
+			"query":   map[string]any{"type": "string"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		coercion ArgumentCoercion
+		args     string
+		want     map[string]any
+	}{
+		{
+			name:     "camel key renamed",
+			coercion: ArgumentCoercion{NormalizeKeys: true},
+			args:     `{"userId": 7, "Query": "go"}`,
+			want:     map[string]any{"user_id": float64(7), "query": "go"},
+		},
+		{
+			name:     "declared key wins over variant",
+			coercion: ArgumentCoercion{NormalizeKeys: true},
+			args:     `{"user_id": 1, "userId": 2}`,
+			want:     map[string]any{"user_id": float64(1), "userId": float64(2)},
+		},
+		{
+			name:     "numeric strings coerced",
+			coercion: ArgumentCoercion{CoerceNumbers: true},
+			args:     `{"user_id": "42", "limit": " 2.5 ", "query": "10"}`,
+			want:     map[string]any{"user_id": float64(42), "limit": 2.5, "query": "10"},
+		},
+		{
+			name:     "unparsable string left alone",
+			coercion: ArgumentCoercion{CoerceNumbers: true},
+			args:     `{"user_id": "seven"}`,
+			want:     map[string]any{"user_id": "seven"},
+		},
+		{
+			name:     "NaN and infinities left alone",
+			coercion: ArgumentCoercion{CoerceNumbers: true},
+			args:     `{"user_id": "NaN", "limit": "-Inf", "query": "x"}`,
+			want:     map[string]any{"user_id": "NaN", "limit": "-Inf", "query": "x"},
+		},
+		{
+			name:     "non-JSON number syntax left alone",
+			coercion: ArgumentCoercion{CoerceNumbers: true},
+			args:     `{"user_id": "0x1p3", "limit": "1_000", "query": "x"}`,
+			want:     map[string]any{"user_id": "0x1p3", "limit": "1_000", "query": "x"},
+		},
+		{
+			name:     "leading plus and overflow left alone",
+			coercion: ArgumentCoercion{CoerceNumbers: true},
+			args:     `{"user_id": "+5", "limit": "1e999"}`,
+			want:     map[string]any{"user_id": "+5", "limit": "1e999"},
+		},
+		{
+			name:     "infinity spelled out left alone",
+			coercion: ArgumentCoercion{CoerceNumbers: true},
+			args:     `{"limit": "infinity"}`,
+			want:     map[string]any{"limit": "infinity"},
+		},
+		{
+			name:     "defaults fill missing and null",
+			coercion: ArgumentCoercion{Defaults: map[string]any{"limit": 10, "query": "all"}},
+			args:     `{"limit": null, "query": "go"}`,
+			want:     map[string]any{"limit": 10, "query": "go"},
+		},
+		{
+			name: "combined",
+			coercion: ArgumentCoercion{
+				NormalizeKeys: true,
+				CoerceNumbers: true,
+				Defaults:      map[string]any{"limit": 5},
+			},
+			args: `{"UserID": "3"}`,
+			want: map[string]any{"user_id": float64(3), "limit": 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]any
+			tool := Tool{
+				Name:             "lookup",
+				Parameters:       params,
+				ArgumentCoercion: &tt.coercion,
+				Callback: func(args map[string]any, _ ContextVariables) (any, error) {
+					got = args
+					return nil, nil
+				},
+			}
+			if _, err := tool.Execute(tt.args, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestArgumentCoercion_DefaultsCopied(t *testing.T) {
+	defaults := map[string]any{
+		"filters": map[string]any{"tags": []any{"go"}},
+		"ids":     []int{1, 2},
+	}
+	tool := Tool{
+		Name:             "search",
+		ArgumentCoercion: &ArgumentCoercion{Defaults: defaults},
+		Callback: func(args map[string]any, _ ContextVariables) (any, error) {
+			filters := args["filters"].(map[string]any)
+			filters["tags"].([]any)[0] = "rust"
+			filters["lang"] = "en"
+			args["ids"].([]int)[0] = 99
+			return nil, nil
+		},
+	}
+
+	for range 2 {
+		if _, err := tool.Execute(`{}`, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := map[string]any{
+		"filters": map[string]any{"tags": []any{"go"}},
+		"ids":     []int{1, 2},
+	}
+	if !reflect.DeepEqual(defaults, want) {
+		t.Errorf("expected defaults to be unchanged, got %v", defaults)
+	}
+}
+
+func TestArgumentCoercion_UseNumber(t *testing.T) {
+	tool := Tool{
+		Name: "count_tool",
+		Parameters: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"count": map[string]any{"type": "integer"}},
+		},
+		UseNumber:        true,
+		ArgumentCoercion: &ArgumentCoercion{CoerceNumbers: true},
+		Callback: func(args map[string]any, _ ContextVariables) (any, error) {
+			if _, ok := args["count"].(json.Number); !ok {
+				t.Errorf("expected json.Number, got %T", args["count"])
+			}
+			return ArgInt(args, "count")
+		},
+	}
+
+	result, err := tool.Execute(`{"count": "9007199254740993"}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != 9007199254740993 {
+		t.Errorf("expected exact integer, got %v", result)
+	}
+}
@@ -1,6 +1,8 @@
 package agents
 
 import (
+	"context"
+	"log/slog"
 	"time"
 
 	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
@@ -13,13 +15,71 @@ type RunConfig struct {
 	MaxTurns int
 
 	// Temperature controls randomness (0.0 to 2.0)
-	// If nil, uses agent's default or model default
+	// Takes precedence over the agent's Temperature unless
+	// PreferAgentSettings is set. If both are nil, uses model default
 	Temperature *float64
 
 	// MaxTokens limits response length
-	// If nil, uses model default
+	// Takes precedence over the agent's MaxTokens unless
+	// PreferAgentSettings is set. If both are nil, uses model default
 	MaxTokens *int
 
+	// TopP, FrequencyPenalty, PresencePenalty and Seed tune sampling like
+	// the agent fields of the same name, which they take precedence over
+	// unless PreferAgentSettings is set. If both are nil, they are not sent
+	TopP             *float64
+	FrequencyPenalty *float64
+	PresencePenalty  *float64
+	Seed             *int64
+
+	// Stop replaces the agent's stop sequences unless PreferAgentSettings
+	// is set. If both are empty, no stop sequences are sent
+	Stop []string
+
+	// PreferAgentSettings reverses the precedence of the model settings
+	// above: the agent's values win and the run config only supplies
+	// them for agents that leave them nil. Use it when agents are configured
+	// centrally and ad-hoc run configs should only provide fallbacks.
+	PreferAgentSettings bool
+
+	// MaxTotalTokens caps the cumulative tokens (prompt + completion) of a run.
+	// When a turn pushes usage over the budget, the run stops with
+	// ErrTokenBudgetExceeded and returns the partial result.
+	// 0 means unlimited
+	MaxTotalTokens int
+
+	// MaxToolCalls caps the number of tool calls executed over a whole run,
+	// across all turns and agents. When the model requests calls that would
+	// exceed it, none of them run: the run stops with
+	// ErrMaxToolCallsExceeded and returns the partial result.
+	// 0 means unlimited
+	MaxToolCalls int
+
+	// MaxHistoryMessages caps how many of the most recent history messages
+	// are sent with each request. System and developer messages are always
+	// kept and not counted. The window is widened rather than cut between an
+	// assistant message and the results of its tool calls.
+	// 0 means unlimited
+	MaxHistoryMessages int
+
+	// MaxHistoryTokens caps the estimated size of the history sent with each
+	// request, dropping the oldest messages first. Sizes are estimated at
+	// four characters of message JSON per token. System and developer
+	// messages are always kept but count against the budget; tool results
+	// are dropped together with the assistant message that requested them,
+	// and the latest message is kept even if it alone exceeds the cap.
+	// 0 means unlimited
+	MaxHistoryTokens int
+
+	// LogitBias maps token IDs to a bias between MinLogitBias and MaxLogitBias.
+	// It is merged with the agent's LogitBias; on conflicts this value wins.
+	LogitBias map[int]int
+
+	// ToolChoice controls whether and which tool the model calls, as
+	// Agent.ToolChoice does. Takes precedence over the agent's ToolChoice
+	// unless PreferAgentSettings is set.
+	ToolChoice string
+
 	// ParallelToolCalls enables concurrent tool execution
 	// Overrides agent's ParallelToolCalls setting if set
 	ParallelToolCalls *bool
@@ -29,11 +89,141 @@ type RunConfig struct {
 
 	// Timeout for the entire agent run
 	// 0 means no timeout
+	// The deadline is set on the context passed to tools, guardrails, routers
+	// and hooks, so they can read the remaining budget with ctx.Deadline()
+	// and shorten their own work to fit
 	Timeout time.Duration
 
+	// MaxRetries is how many times a failed LLM call is retried when the
+	// error is transient: rate limits (429), timeouts, conflicts, server
+	// errors and network failures. Other errors, such as an invalid API key,
+	// fail the run right away. These retries come on top of the openai-go
+	// client's own. With RunStreamTo, output of a stream that fails midway
+	// is written again by the retry.
+	// 0 disables retries
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry; it doubles on every
+	// further attempt, with jitter. A Retry-After hint from the server takes
+	// precedence. Retries stop when the run's deadline would pass first.
+	// If 0, DefaultRetryBackoff is used
+	RetryBackoff time.Duration
+
+	// APIKey authenticates the run's API calls in place of the client's
+	// key, e.g. to run agents with each customer's own key. It applies to
+	// this run only, so concurrent runs on one Runner can use different keys.
+	APIKey string
+
 	// ResponseFormat can override agent's response format
 	// If nil, uses agent's ResponseFormat
 	ResponseFormat *jsonschema.ResponseFormat
+
+	// DisableStrictOutput sends structured output schemas with strict mode
+	// off, regardless of the schema's own setting. Use it with models or
+	// providers that reject strict mode.
+	DisableStrictOutput bool
+
+	// StoreResponses asks OpenAI to store the run's chat completions so they
+	// can be retrieved later or used in evals. The IDs are available as
+	// Step.ResponseID and Result.ResponseID.
+	StoreResponses bool
+
+	// Metadata tags the run's chat completions, e.g. for filtering stored
+	// completions in the dashboard.
+	Metadata map[string]string
+
+	// EndUserID is a stable identifier of the end user the run acts for,
+	// sent as the "user" field so OpenAI can attribute abuse and rate
+	// limits per user. Use an opaque or hashed ID rather than personal data.
+	EndUserID string
+
+	// SuppressSystemMessage disables injection of the agent's instructions
+	// as a system message. Use this when the caller manages the system prompt
+	// entirely through the messages passed to Run.
+	SuppressSystemMessage bool
+
+	// InjectScratchpad sends the run's scratchpad (see
+	// ContextVariables.AppendScratchpad) to the model as a system note at
+	// the end of every request. The note is not added to the history.
+	InjectScratchpad bool
+
+	// ConversationGuardrails check the full history before every turn of
+	// the run, whichever agent is active. When one trips, the run stops with
+	// a *GuardrailTrippedError and StopReasonGuardrail.
+	ConversationGuardrails []ConversationGuardrail
+
+	// OutputGuardrails check the final output of the run, before those in
+	// Agent.OutputGuardrails of the agent that produced it.
+	OutputGuardrails []OutputGuardrail
+
+	// RefusalFallback replaces the FinalOutput of a run whose final reply is
+	// a refusal, so applications can show a consistent message. The model's
+	// refusal is still available as Result.Refusal. If empty, FinalOutput
+	// holds the refusal text.
+	RefusalFallback string
+
+	// OnRefusal is called when the final reply is a refusal, after
+	// RefusalFallback is applied. Returning an error ends the run with that
+	// error and the result, e.g. to treat refusals like a tripped guardrail.
+	OnRefusal func(ctx context.Context, agent *Agent, refusal string) error
+
+	// Logger is the base logger for the run. The runner tags it with the run ID
+	// and makes it available to tools and hooks via LoggerFromContext.
+	// If nil, slog.Default() is used.
+	Logger *slog.Logger
+
+	// PredictedOutput is known content the response is expected to largely
+	// match (e.g. the original file in a code-rewrite task). It is sent as a
+	// predicted output to reduce latency when the model supports it, and is
+	// ignored otherwise.
+	PredictedOutput string
+
+	// ContextVariables seeds well-known context variables for the run.
+	// Seeds are merged into the variables passed to Run; on key conflicts
+	// the values passed to Run take precedence.
+	ContextVariables ContextVariables
+
+	// DeleteFiles lists uploaded file IDs to delete once the run finishes,
+	// whether it succeeds or fails. Useful for one-shot document Q&A.
+	DeleteFiles []string
+
+	// ToolResultFormatter converts a tool call into the content of the tool
+	// message sent back to the model. The call's Result is the value the model
+	// would otherwise see (e.g. "Transferred to X" for handoffs).
+	// If nil, strings are sent as they are and other results as JSON,
+	// falling back to fmt.Sprintf("%v") when they cannot be marshaled. If the
+	// formatter panics, e.g. on a result it cannot serialize, the model gets
+	// an error message and the call records ErrUnformattableResult.
+	ToolResultFormatter func(ToolCall) string
+
+	// Summarizer is the agent, typically on a cheaper model, that condenses
+	// results of tools with SummarizeResult set. Its instructions are used as
+	// the system prompt; its tools are ignored. Summarization calls count
+	// towards the run's usage. If nil, results are not summarized.
+	Summarizer *Agent
+
+	// OnToolCallRequested is called by RunStreamTo as soon as a tool call has
+	// been fully streamed, before any tool of the turn runs, e.g. to show
+	// "Calling get_weather(Paris)..." in a UI. Run does not call it.
+	OnToolCallRequested func(ctx context.Context, call ToolCallRequested)
+
+	// EventSink receives the significant events of the run as they happen:
+	// LLM calls, tool runs, handoffs, guardrail checks and completed steps.
+	// It is called synchronously from the run, so it should be fast. Use
+	// JSONLinesSink to write events to a log as JSON lines.
+	EventSink func(Event)
+
+	// Recorder captures every LLM call of the run for later use with
+	// Runner.Replay. If nil, nothing is recorded.
+	Recorder *Recorder
+
+	// ConsolidateToolResults collects the results of all tool calls of a
+	// turn in a single tool message holding one content block per call, for
+	// providers that expect a combined result. The message uses the first
+	// call's ID and each block is prefixed with its own call ID. Since the
+	// API requires a tool message for every call ID, each other call is
+	// answered with a short stub referring to the first call's ID.
+	ConsolidateToolResults bool
 }
 
 // DefaultRunConfig returns sensible defaults
@@ -52,28 +242,164 @@ func (c *RunConfig) Merge(overrides *RunConfig) *RunConfig {
 	}
 
 	result := *c // copy
+	mergeModelSettings(&result, overrides)
+	mergeLimits(&result, overrides)
+	mergeHooks(&result, overrides)
 
-	if overrides.MaxTurns > 0 {
-		result.MaxTurns = overrides.MaxTurns
+	if overrides.Debug {
+		result.Debug = true
+	}
+	if overrides.APIKey != "" {
+		result.APIKey = overrides.APIKey
+	}
+	if overrides.StoreResponses {
+		result.StoreResponses = true
+	}
+	if len(overrides.Metadata) > 0 {
+		result.Metadata = mergeMaps(c.Metadata, overrides.Metadata)
+	}
+	if overrides.EndUserID != "" {
+		result.EndUserID = overrides.EndUserID
+	}
+	if overrides.SuppressSystemMessage {
+		result.SuppressSystemMessage = true
 	}
+	if overrides.InjectScratchpad {
+		result.InjectScratchpad = true
+	}
+	if len(overrides.ContextVariables) > 0 {
+		result.ContextVariables = mergeMaps(c.ContextVariables, overrides.ContextVariables)
+	}
+	if len(overrides.DeleteFiles) > 0 {
+		result.DeleteFiles = overrides.DeleteFiles
+	}
+	if overrides.ConsolidateToolResults {
+		result.ConsolidateToolResults = true
+	}
+
+	return &result
+}
+
+// mergeModelSettings applies the overrides of the request parameters sent
+// to the model.
+func mergeModelSettings(result, overrides *RunConfig) {
 	if overrides.Temperature != nil {
 		result.Temperature = overrides.Temperature
 	}
 	if overrides.MaxTokens != nil {
 		result.MaxTokens = overrides.MaxTokens
 	}
+	if overrides.TopP != nil {
+		result.TopP = overrides.TopP
+	}
+	if overrides.FrequencyPenalty != nil {
+		result.FrequencyPenalty = overrides.FrequencyPenalty
+	}
+	if overrides.PresencePenalty != nil {
+		result.PresencePenalty = overrides.PresencePenalty
+	}
+	if overrides.Seed != nil {
+		result.Seed = overrides.Seed
+	}
+	if len(overrides.Stop) > 0 {
+		result.Stop = overrides.Stop
+	}
+	if overrides.PreferAgentSettings {
+		result.PreferAgentSettings = true
+	}
+	if len(overrides.LogitBias) > 0 {
+		result.LogitBias = mergeMaps(result.LogitBias, overrides.LogitBias)
+	}
+	if overrides.ToolChoice != "" {
+		result.ToolChoice = overrides.ToolChoice
+	}
 	if overrides.ParallelToolCalls != nil {
 		result.ParallelToolCalls = overrides.ParallelToolCalls
 	}
-	if overrides.Debug {
-		result.Debug = true
+	if overrides.ResponseFormat != nil {
+		result.ResponseFormat = overrides.ResponseFormat
+	}
+	if overrides.DisableStrictOutput {
+		result.DisableStrictOutput = true
+	}
+	if overrides.PredictedOutput != "" {
+		result.PredictedOutput = overrides.PredictedOutput
+	}
+}
+
+// mergeLimits applies the overrides of the run's turn, token, time and
+// retry limits.
+func mergeLimits(result, overrides *RunConfig) {
+	if overrides.MaxTurns > 0 {
+		result.MaxTurns = overrides.MaxTurns
+	}
+	if overrides.MaxTotalTokens > 0 {
+		result.MaxTotalTokens = overrides.MaxTotalTokens
+	}
+	if overrides.MaxToolCalls > 0 {
+		result.MaxToolCalls = overrides.MaxToolCalls
+	}
+	if overrides.MaxHistoryMessages > 0 {
+		result.MaxHistoryMessages = overrides.MaxHistoryMessages
+	}
+	if overrides.MaxHistoryTokens > 0 {
+		result.MaxHistoryTokens = overrides.MaxHistoryTokens
 	}
 	if overrides.Timeout > 0 {
 		result.Timeout = overrides.Timeout
 	}
-	if overrides.ResponseFormat != nil {
-		result.ResponseFormat = overrides.ResponseFormat
+	if overrides.MaxRetries > 0 {
+		result.MaxRetries = overrides.MaxRetries
+	}
+	if overrides.RetryBackoff > 0 {
+		result.RetryBackoff = overrides.RetryBackoff
 	}
+}
 
-	return &result
+// mergeHooks applies the overrides of the guardrails, callbacks and other
+// hooks into the run.
+func mergeHooks(result, overrides *RunConfig) {
+	if len(overrides.ConversationGuardrails) > 0 {
+		result.ConversationGuardrails = overrides.ConversationGuardrails
+	}
+	if len(overrides.OutputGuardrails) > 0 {
+		result.OutputGuardrails = overrides.OutputGuardrails
+	}
+	if overrides.RefusalFallback != "" {
+		result.RefusalFallback = overrides.RefusalFallback
+	}
+	if overrides.OnRefusal != nil {
+		result.OnRefusal = overrides.OnRefusal
+	}
+	if overrides.Logger != nil {
+		result.Logger = overrides.Logger
+	}
+	if overrides.ToolResultFormatter != nil {
+		result.ToolResultFormatter = overrides.ToolResultFormatter
+	}
+	if overrides.Summarizer != nil {
+		result.Summarizer = overrides.Summarizer
+	}
+	if overrides.OnToolCallRequested != nil {
+		result.OnToolCallRequested = overrides.OnToolCallRequested
+	}
+	if overrides.EventSink != nil {
+		result.EventSink = overrides.EventSink
+	}
+	if overrides.Recorder != nil {
+		result.Recorder = overrides.Recorder
+	}
+}
+
+// mergeMaps returns a new map with the entries of base and override; on key
+// conflicts the override wins.
+func mergeMaps[M ~map[K]V, K comparable, V any](base, override M) M {
+	merged := make(M, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
 }
@@ -1,6 +1,7 @@
 package agents
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -66,6 +67,55 @@ func TestRunConfigMerge(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "override sampling settings",
+			base:     &RunConfig{TopP: floatPtr(0.5), Seed: int64Ptr(1)},
+			override: &RunConfig{FrequencyPenalty: floatPtr(0.3), PresencePenalty: floatPtr(-0.3), Seed: int64Ptr(42)},
+			validate: func(t *testing.T, result *RunConfig) {
+				if result.TopP == nil || *result.TopP != 0.5 {
+					t.Errorf("expected TopP=0.5 kept, got %v", result.TopP)
+				}
+				if result.FrequencyPenalty == nil || *result.FrequencyPenalty != 0.3 {
+					t.Errorf("expected FrequencyPenalty=0.3, got %v", result.FrequencyPenalty)
+				}
+				if result.PresencePenalty == nil || *result.PresencePenalty != -0.3 {
+					t.Errorf("expected PresencePenalty=-0.3, got %v", result.PresencePenalty)
+				}
+				if result.Seed == nil || *result.Seed != 42 {
+					t.Errorf("expected Seed=42, got %v", result.Seed)
+				}
+			},
+		},
+		{
+			name:     "override MaxToolCalls",
+			base:     &RunConfig{MaxToolCalls: 5},
+			override: &RunConfig{MaxToolCalls: 20},
+			validate: func(t *testing.T, result *RunConfig) {
+				if result.MaxToolCalls != 20 {
+					t.Errorf("expected MaxToolCalls=20, got %d", result.MaxToolCalls)
+				}
+			},
+		},
+		{
+			name:     "override Stop",
+			base:     &RunConfig{Stop: []string{"END"}},
+			override: &RunConfig{Stop: []string{"---"}},
+			validate: func(t *testing.T, result *RunConfig) {
+				if !reflect.DeepEqual(result.Stop, []string{"---"}) {
+					t.Errorf("expected Stop=[---], got %v", result.Stop)
+				}
+			},
+		},
+		{
+			name:     "override ToolChoice",
+			base:     &RunConfig{ToolChoice: ToolChoiceRequired},
+			override: &RunConfig{ToolChoice: "lookup"},
+			validate: func(t *testing.T, result *RunConfig) {
+				if result.ToolChoice != "lookup" {
+					t.Errorf("expected ToolChoice=lookup, got %q", result.ToolChoice)
+				}
+			},
+		},
 		{
 			name:     "override MaxTokens",
 			base:     &RunConfig{},
@@ -96,6 +146,72 @@ func TestRunConfigMerge(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:     "override SuppressSystemMessage",
+			base:     &RunConfig{},
+			override: &RunConfig{SuppressSystemMessage: true},
+			validate: func(t *testing.T, result *RunConfig) {
+				if !result.SuppressSystemMessage {
+					t.Error("expected SuppressSystemMessage=true")
+				}
+			},
+		},
+		{
+			name:     "override DisableStrictOutput",
+			base:     &RunConfig{},
+			override: &RunConfig{DisableStrictOutput: true},
+			validate: func(t *testing.T, result *RunConfig) {
+				if !result.DisableStrictOutput {
+					t.Error("expected DisableStrictOutput=true")
+				}
+			},
+		},
+		{
+			name:     "override ConsolidateToolResults",
+			base:     &RunConfig{},
+			override: &RunConfig{ConsolidateToolResults: true},
+			validate: func(t *testing.T, result *RunConfig) {
+				if !result.ConsolidateToolResults {
+					t.Error("expected ConsolidateToolResults=true")
+				}
+			},
+		},
+		{
+			name:     "merge LogitBias",
+			base:     &RunConfig{LogitBias: map[int]int{1: 10, 2: 20}},
+			override: &RunConfig{LogitBias: map[int]int{2: -50}},
+			validate: func(t *testing.T, result *RunConfig) {
+				if result.LogitBias[1] != 10 || result.LogitBias[2] != -50 {
+					t.Errorf("expected merged bias with override winning, got %v", result.LogitBias)
+				}
+			},
+		},
+		{
+			name:     "merge Metadata",
+			base:     &RunConfig{Metadata: map[string]string{"team": "a", "env": "dev"}},
+			override: &RunConfig{StoreResponses: true, Metadata: map[string]string{"env": "prod"}},
+			validate: func(t *testing.T, result *RunConfig) {
+				if !result.StoreResponses {
+					t.Error("expected StoreResponses=true")
+				}
+				if result.Metadata["team"] != "a" || result.Metadata["env"] != "prod" {
+					t.Errorf("expected merged metadata with override winning, got %v", result.Metadata)
+				}
+			},
+		},
+		{
+			name:     "merge ContextVariables",
+			base:     &RunConfig{ContextVariables: ContextVariables{"tenant": "a", "role": "user"}},
+			override: &RunConfig{ContextVariables: ContextVariables{"role": "admin"}},
+			validate: func(t *testing.T, result *RunConfig) {
+				if result.ContextVariables["tenant"] != "a" {
+					t.Errorf("expected tenant=a, got %v", result.ContextVariables["tenant"])
+				}
+				if result.ContextVariables["role"] != "admin" {
+					t.Errorf("expected role=admin, got %v", result.ContextVariables["role"])
+				}
+			},
+		},
 		{
 			name:     "zero values don't override",
 			base:     &RunConfig{MaxTurns: 10},
@@ -124,3 +240,7 @@ func floatPtr(f float64) *float64 {
 func intPtr(i int) *int {
 	return &i
 }
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
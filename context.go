@@ -0,0 +1,66 @@
+package agents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/openai/openai-go/option"
+)
+
+type runIDKey struct{}
+
+type loggerKey struct{}
+
+type apiKeyKey struct{}
+
+// RunIDFromContext returns the ID of the run the context belongs to.
+// It returns an empty string when the context was not created by Runner.Run.
+func RunIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(runIDKey{}).(string)
+	return id
+}
+
+// LoggerFromContext returns the run-scoped logger carried by the context.
+// The logger is pre-populated with a "run_id" attribute. If the context
+// carries no logger, slog.Default() is returned.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// withRunContext stores the run ID and a logger tagged with it in ctx.
+func withRunContext(ctx context.Context, runID string, logger *slog.Logger) context.Context {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx = context.WithValue(ctx, runIDKey{}, runID)
+	return context.WithValue(ctx, loggerKey{}, logger.With("run_id", runID))
+}
+
+// withAPIKey makes API calls made with ctx use apiKey instead of the
+// client's key. An empty key leaves ctx unchanged.
+func withAPIKey(ctx context.Context, apiKey string) context.Context {
+	if apiKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyKey{}, apiKey)
+}
+
+// requestOptions returns the per-call options carried by ctx.
+func requestOptions(ctx context.Context) []option.RequestOption {
+	if apiKey, ok := ctx.Value(apiKeyKey{}).(string); ok {
+		return []option.RequestOption{option.WithAPIKey(apiKey)}
+	}
+	return nil
+}
+
+// newRunID generates a random identifier for a run.
+func newRunID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "run_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,44 @@
+package agents
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRunIDFromContext(t *testing.T) {
+	if id := RunIDFromContext(context.Background()); id != "" {
+		t.Errorf("expected empty run ID, got %q", id)
+	}
+
+	ctx := withRunContext(context.Background(), "run_123", nil)
+	if id := RunIDFromContext(ctx); id != "run_123" {
+		t.Errorf("expected run_123, got %q", id)
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	if LoggerFromContext(context.Background()) != slog.Default() {
+		t.Error("expected default logger for bare context")
+	}
+
+	var buf strings.Builder
+	base := slog.New(slog.NewTextHandler(&buf, nil))
+	ctx := withRunContext(context.Background(), "run_abc", base)
+
+	LoggerFromContext(ctx).Info("hello")
+	if !strings.Contains(buf.String(), "run_id=run_abc") {
+		t.Errorf("expected log line tagged with run ID, got %q", buf.String())
+	}
+}
+
+func TestNewRunIDUnique(t *testing.T) {
+	a, b := newRunID(), newRunID()
+	if a == b {
+		t.Errorf("expected unique run IDs, got %q twice", a)
+	}
+	if !strings.HasPrefix(a, "run_") {
+		t.Errorf("expected run_ prefix, got %q", a)
+	}
+}
@@ -0,0 +1,83 @@
+package agents
+
+import (
+	"context"
+	"sync"
+
+	"github.com/openai/openai-go"
+)
+
+// Conversation is a multi-turn chat with an agent. It keeps the message
+// history in memory between calls to Send, and after a handoff it keeps
+// talking to the agent that took over.
+//
+// A Conversation is safe for concurrent use, but turns are serialized.
+type Conversation struct {
+	runner *Runner
+	agent  *Agent
+	config *RunConfig
+	vars   ContextVariables
+
+	mu           sync.Mutex
+	currentAgent *Agent
+	history      []openai.ChatCompletionMessageParamUnion
+}
+
+// NewConversation creates a conversation with the given agent. The config may
+// be nil, in which case the runner's defaults are used.
+func NewConversation(runner *Runner, agent *Agent, config *RunConfig) *Conversation {
+	return &Conversation{
+		runner:       runner,
+		agent:        agent,
+		config:       config,
+		vars:         make(ContextVariables),
+		currentAgent: agent,
+	}
+}
+
+// Send adds a user message to the conversation and runs the current agent.
+// The history is only updated when the run succeeds, so a failed turn can
+// simply be retried.
+func (c *Conversation) Send(ctx context.Context, text string) (*Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	messages := append(c.history[:len(c.history):len(c.history)], openai.UserMessage(text))
+	result, err := c.runner.Run(ctx, c.currentAgent, messages, c.vars, c.config)
+	if err != nil {
+		return result, err
+	}
+
+	c.history = result.Messages
+	c.currentAgent = result.Agent
+	return result, nil
+}
+
+// History returns a copy of the messages exchanged so far.
+func (c *Conversation) History() []openai.ChatCompletionMessageParamUnion {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]openai.ChatCompletionMessageParamUnion(nil), c.history...)
+}
+
+// Agent returns the agent that will handle the next message.
+func (c *Conversation) Agent() *Agent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.currentAgent
+}
+
+// ContextVariables returns the variables shared across all turns.
+func (c *Conversation) ContextVariables() ContextVariables {
+	return c.vars
+}
+
+// Reset clears the history and context variables and hands the conversation
+// back to the agent it started with.
+func (c *Conversation) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.history = nil
+	c.currentAgent = c.agent
+	clear(c.vars)
+}
@@ -0,0 +1,104 @@
+package agents
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConversation_SendKeepsHistoryAndAgent(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "transfer", Arguments: `{}`}),
+		textCompletion("hello from second"),
+		textCompletion("still second"),
+	)
+
+	second := NewAgent("Second")
+	first := NewAgent("First")
+	first.Tools = []Tool{
+		FunctionTool("transfer", "Transfer", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return second, nil
+		}),
+	}
+
+	conv := NewConversation(runner, first, nil)
+
+	result, err := conv.Send(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("first Send failed: %v", err)
+	}
+	if result.FinalOutput != "hello from second" {
+		t.Errorf("unexpected output %q", result.FinalOutput)
+	}
+	if conv.Agent() != second {
+		t.Fatalf("expected conversation to continue with Second, got %s", conv.Agent().Name)
+	}
+	turn1 := len(conv.History())
+
+	if _, err := conv.Send(context.Background(), "again"); err != nil {
+		t.Fatalf("second Send failed: %v", err)
+	}
+	if got := len(conv.History()); got != turn1+2 {
+		t.Errorf("expected history to grow by 2 messages, got %d -> %d", turn1, got)
+	}
+
+	reqs := mock.Requests()
+	sent := reqs[len(reqs)-1]["messages"].([]any)
+	lastMsg := sent[len(sent)-1].(map[string]any)
+	if lastMsg["role"] != "user" || lastMsg["content"] != "again" {
+		t.Errorf("expected the new user message last, got %v", lastMsg)
+	}
+	if len(sent) <= turn1 {
+		t.Errorf("expected request to carry the previous %d messages, got %d", turn1, len(sent))
+	}
+}
+
+func TestConversation_FailedSendLeavesHistory(t *testing.T) {
+	runner, _ := newMockRunner(t, textCompletion("ok"))
+
+	conv := NewConversation(runner, NewAgent("Assistant"), nil)
+	if _, err := conv.Send(context.Background(), "one"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	before := len(conv.History())
+
+	// The mock has no responses left, so this turn fails
+	if _, err := conv.Send(context.Background(), "two"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := len(conv.History()); got != before {
+		t.Errorf("expected history to stay at %d messages, got %d", before, got)
+	}
+}
+
+func TestConversation_Reset(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "transfer", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	second := NewAgent("Second")
+	first := NewAgent("First")
+	first.Tools = []Tool{
+		FunctionTool("transfer", "Transfer", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+			vars["visited"] = true
+			return second, nil
+		}),
+	}
+
+	conv := NewConversation(runner, first, nil)
+	if _, err := conv.Send(context.Background(), "hi"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	conv.Reset()
+
+	if n := len(conv.History()); n != 0 {
+		t.Errorf("expected empty history after Reset, got %d messages", n)
+	}
+	if conv.Agent() != first {
+		t.Errorf("expected Reset to restore the starting agent, got %s", conv.Agent().Name)
+	}
+	if len(conv.ContextVariables()) != 0 {
+		t.Errorf("expected Reset to clear context variables, got %v", conv.ContextVariables())
+	}
+}
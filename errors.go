@@ -3,6 +3,14 @@ package agents
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
 var (
@@ -14,6 +22,52 @@ var (
 
 	// ErrNoMessages is returned when Run is called with empty messages
 	ErrNoMessages = errors.New("no messages provided")
+
+	// ErrTokenBudgetExceeded is returned when a run uses more than RunConfig.MaxTotalTokens
+	ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+	// ErrMaxToolCallsExceeded is returned when a run requests more tool calls than RunConfig.MaxToolCalls
+	ErrMaxToolCallsExceeded = errors.New("maximum tool calls exceeded")
+
+	// ErrUnexpectedToolCall is returned when the model calls a tool but the agent has none
+	ErrUnexpectedToolCall = errors.New("model called a tool but the agent has no tools")
+
+	// ErrReplayExhausted is returned when a replayed run needs more LLM calls than were recorded
+	ErrReplayExhausted = errors.New("replay ran out of recorded turns")
+
+	// ErrToolSkipped is recorded on a ToolCall that Agent.OnToolStart prevented from running
+	ErrToolSkipped = errors.New("tool call skipped by OnToolStart")
+
+	// ErrToolTimeout is returned for a tool call that exceeds Tool.Timeout
+	ErrToolTimeout = errors.New("tool timed out")
+
+	// ErrUnformattableResult is recorded on a ToolCall whose result could not
+	// be turned into tool message content
+	ErrUnformattableResult = errors.New("tool result could not be formatted")
+
+	// ErrNoChoices is returned when the LLM response contains no choices
+	ErrNoChoices = errors.New("completion returned no choices")
+
+	// ErrNoExtraction is returned by Extract when the model does not call the extract tool
+	ErrNoExtraction = errors.New("model did not call the extract tool")
+
+	// ErrUnknownPricing is returned by Usage.Cost for models without a registered price
+	ErrUnknownPricing = errors.New("no pricing registered for model")
+
+	// ErrNoClient is returned by streaming, Ping and the Files API on a
+	// Runner without a Client
+	ErrNoClient = errors.New("runner has no client")
+
+	// ErrInvalidToolChoice is returned when a tool choice names a tool the agent
+	// does not have, or requires a tool call from an agent without tools
+	ErrInvalidToolChoice = errors.New("invalid tool choice")
+
+	// ErrInvalidHandoff is returned when one of an agent's handoffs has no agent
+	ErrInvalidHandoff = errors.New("invalid handoff")
+
+	// ErrDuplicateTool is returned when two of an agent's tools, including
+	// the tools of its handoffs, have the same name
+	ErrDuplicateTool = errors.New("duplicate tool name")
 )
 
 // ToolExecutionError wraps errors from tool execution
@@ -53,3 +107,114 @@ func (e *OutputValidationError) Error() string {
 func (e *OutputValidationError) Unwrap() error {
 	return e.Err
 }
+
+// GuardrailTrippedError is returned when a ConversationGuardrail rejects
+// the conversation or an OutputGuardrail rejects the final output.
+type GuardrailTrippedError struct {
+	Guardrail string
+	Err       error
+}
+
+func (e *GuardrailTrippedError) Error() string {
+	return fmt.Sprintf("guardrail %s tripped: %v", e.Guardrail, e.Err)
+}
+
+func (e *GuardrailTrippedError) Unwrap() error {
+	return e.Err
+}
+
+// SchemaViolationError lists where a structured output breaks the schema
+// of its response format.
+type SchemaViolationError struct {
+	Violations []jsonschema.Violation
+}
+
+func (e *SchemaViolationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "output does not match schema: " + strings.Join(parts, "; ")
+}
+
+// TruncatedOutputError is returned by Result.Into when the structured output
+// was cut off by the token limit. If Recovered is true, the value passed to
+// Into holds the fields that could be decoded from the repaired output.
+type TruncatedOutputError struct {
+	// Output is the raw, truncated model output
+	Output string
+
+	// Recovered reports whether a partial value was decoded
+	Recovered bool
+
+	// Err is the error decoding the unrepaired output
+	Err error
+}
+
+func (e *TruncatedOutputError) Error() string {
+	if e.Recovered {
+		return "output truncated by token limit: decoded partial value"
+	}
+	return fmt.Sprintf("output truncated by token limit: %v", e.Err)
+}
+
+func (e *TruncatedOutputError) Unwrap() error {
+	return e.Err
+}
+
+// maxOutputSnippet bounds how much model output is quoted in errors
+const maxOutputSnippet = 100
+
+// newOutputDecodeError builds an OutputValidationError quoting a snippet of the output.
+func newOutputDecodeError(expected, output string, err error) error {
+	got := output
+	if len(got) > maxOutputSnippet {
+		got = got[:maxOutputSnippet] + "..."
+	}
+	return &OutputValidationError{
+		Expected: expected,
+		Got:      fmt.Sprintf("%q", got),
+		Err:      err,
+	}
+}
+
+// RetryAfter returns the delay the server asked for before retrying a failed
+// API call, read from the Retry-After-Ms or Retry-After response header.
+// Retry-After may be given in seconds or as an HTTP date. It returns false
+// when err is not an API error or carries no usable retry hint.
+//
+// Note that the openai-go client already honors these headers for its own
+// built-in retries, and so do the runner's retries (RunConfig.MaxRetries);
+// RetryAfter is for callers implementing their own.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) || apiErr.Response == nil {
+		return 0, false
+	}
+	return parseRetryAfter(apiErr.Response.Header, time.Now())
+}
+
+// parseRetryAfter reads retry hints from response headers relative to now.
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if ms := header.Get("Retry-After-Ms"); ms != "" {
+		if v, err := strconv.ParseFloat(ms, 64); err == nil && v >= 0 {
+			return time.Duration(v * float64(time.Millisecond)), true
+		}
+	}
+
+	ra := header.Get("Retry-After")
+	if ra == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(ra, 64); err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(ra); err == nil {
+		d := at.Sub(now)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
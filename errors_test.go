@@ -2,7 +2,12 @@ package agents
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
 )
 
 func TestSentinelErrors(t *testing.T) {
@@ -26,6 +31,21 @@ func TestSentinelErrors(t *testing.T) {
 			err:  ErrNoMessages,
 			msg:  "no messages provided",
 		},
+		{
+			name: "ErrTokenBudgetExceeded",
+			err:  ErrTokenBudgetExceeded,
+			msg:  "token budget exceeded",
+		},
+		{
+			name: "ErrUnexpectedToolCall",
+			err:  ErrUnexpectedToolCall,
+			msg:  "model called a tool but the agent has no tools",
+		},
+		{
+			name: "ErrReplayExhausted",
+			err:  ErrReplayExhausted,
+			msg:  "replay ran out of recorded turns",
+		},
 	}
 
 	for _, tt := range tests {
@@ -82,3 +102,80 @@ func TestOutputValidationError(t *testing.T) {
 		t.Error("expected error to unwrap to base error")
 	}
 }
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "seconds",
+			header: http.Header{"Retry-After": []string{"3"}},
+			want:   3 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "milliseconds take precedence",
+			header: http.Header{"Retry-After-Ms": []string{"250"}, "Retry-After": []string{"3"}},
+			want:   250 * time.Millisecond,
+			wantOK: true,
+		},
+		{
+			name:   "HTTP date",
+			header: http.Header{"Retry-After": []string{now.Add(5 * time.Second).Format(http.TimeFormat)}},
+			want:   5 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "date in the past",
+			header: http.Header{"Retry-After": []string{now.Add(-time.Minute).Format(http.TimeFormat)}},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "missing header",
+			header: http.Header{},
+			wantOK: false,
+		},
+		{
+			name:   "garbage",
+			header: http.Header{"Retry-After": []string{"soon"}},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.header, now)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryAfterFromAPIError(t *testing.T) {
+	apiErr := &openai.Error{
+		StatusCode: http.StatusTooManyRequests,
+		Response: &http.Response{
+			Header: http.Header{"Retry-After": []string{"2"}},
+		},
+	}
+	wrapped := fmt.Errorf("LLM call failed: %w", apiErr)
+
+	got, ok := RetryAfter(wrapped)
+	if !ok || got != 2*time.Second {
+		t.Errorf("expected 2s, got %v (ok=%v)", got, ok)
+	}
+
+	if _, ok := RetryAfter(errors.New("plain")); ok {
+		t.Error("expected no retry hint for non-API error")
+	}
+}
@@ -0,0 +1,119 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened in a run event.
+type EventType string
+
+const (
+	// EventLLMRequest is emitted before every LLM call of the agent loop
+	EventLLMRequest EventType = "llm_request"
+
+	// EventLLMResponse is emitted when an LLM call returns or fails
+	EventLLMResponse EventType = "llm_response"
+
+	// EventToolStart is emitted before a tool runs
+	EventToolStart EventType = "tool_start"
+
+	// EventToolEnd is emitted after a tool has run
+	EventToolEnd EventType = "tool_end"
+
+	// EventHandoff is emitted when a tool hands off to another agent
+	EventHandoff EventType = "handoff"
+
+	// EventGuardrail is emitted for every conversation guardrail checked
+	EventGuardrail EventType = "guardrail"
+
+	// EventStepComplete is emitted at the end of every turn
+	EventStepComplete EventType = "step_complete"
+
+	// EventRunEnd is emitted when the run stops, with its stop reason
+	EventRunEnd EventType = "run_end"
+)
+
+// Event is a significant moment of a run, reported to RunConfig.EventSink.
+type Event struct {
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	RunID string    `json:"run_id"`
+
+	// Agent is the name of the agent active when the event happened
+	Agent string `json:"agent,omitempty"`
+
+	// Turn is the number of the turn the event belongs to, starting at 1
+	Turn int `json:"turn,omitempty"`
+
+	// Data holds the event's details, e.g. the tool name and duration
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// JSONLinesSink returns an event sink that writes every event to w as a
+// line of JSON, e.g. to pipe runs into a log pipeline. It is safe for use
+// by concurrent runs. Write errors are ignored so logging never fails a run.
+func JSONLinesSink(w io.Writer) func(Event) {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(e)
+	}
+}
+
+type eventsKey struct{}
+
+// runEvents emits the events of one run. A nil *runEvents emits nothing.
+type runEvents struct {
+	sink  func(Event)
+	runID string
+	turn  int
+}
+
+// withRunEvents attaches an emitter for sink to ctx. It returns a nil
+// emitter when sink is nil.
+func withRunEvents(ctx context.Context, runID string, sink func(Event)) (context.Context, *runEvents) {
+	if sink == nil {
+		return ctx, nil
+	}
+	events := &runEvents{sink: sink, runID: runID}
+	return context.WithValue(ctx, eventsKey{}, events), events
+}
+
+// eventsFromContext returns the run's emitter, or nil if it has none.
+func eventsFromContext(ctx context.Context) *runEvents {
+	events, _ := ctx.Value(eventsKey{}).(*runEvents)
+	return events
+}
+
+// setTurn sets the turn reported by later events.
+func (e *runEvents) setTurn(turn int) {
+	if e != nil {
+		e.turn = turn
+	}
+}
+
+func (e *runEvents) emit(typ EventType, agent *Agent, data map[string]any) {
+	if e == nil {
+		return
+	}
+	event := Event{Type: typ, Time: time.Now(), RunID: e.runID, Turn: e.turn, Data: data}
+	if agent != nil {
+		event.Agent = agent.Name
+	}
+	e.sink(event)
+}
+
+// stepEventData summarizes a completed step for EventStepComplete.
+func stepEventData(step Step) map[string]any {
+	return map[string]any{
+		"duration_ms": step.Duration.Milliseconds(),
+		"tool_calls":  len(step.ToolCalls),
+		"usage":       step.Usage,
+	}
+}
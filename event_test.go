@@ -0,0 +1,82 @@
+package agents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestRun_EventSink(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "lookup", Arguments: `{"q": "go"}`}),
+		textCompletion("done"),
+	)
+
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionTool("lookup", "Looks things up", nil, func(map[string]any, ContextVariables) (any, error) {
+			return "found", nil
+		}),
+	}
+	agent.ConversationGuardrails = []ConversationGuardrail{{
+		Name:          "allow",
+		Func:          func(context.Context, []openai.ChatCompletionMessageParamUnion) error { return nil },
+		FirstTurnOnly: true,
+	}}
+
+	var events []Event
+	config := &RunConfig{MaxTurns: 5, EventSink: func(e Event) { events = append(events, e) }}
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("search")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var types []EventType
+	for _, e := range events {
+		types = append(types, e.Type)
+		if e.RunID != result.RunID || e.Agent != "Assistant" {
+			t.Errorf("expected run ID and agent on %s event, got %+v", e.Type, e)
+		}
+	}
+	want := []EventType{
+		EventGuardrail, EventLLMRequest, EventLLMResponse, EventToolStart, EventToolEnd, EventStepComplete,
+		EventLLMRequest, EventLLMResponse, EventStepComplete, EventRunEnd,
+	}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("unexpected events:\n got %v\nwant %v", types, want)
+	}
+	if events[3].Turn != 1 || events[3].Data["tool"] != "lookup" || events[3].Data["arguments"] != `{"q": "go"}` {
+		t.Errorf("unexpected tool start event %+v", events[3])
+	}
+	if events[6].Turn != 2 {
+		t.Errorf("expected second LLM request on turn 2, got %d", events[6].Turn)
+	}
+	if events[9].Data["stop_reason"] != StopReasonCompleted {
+		t.Errorf("unexpected run end event %+v", events[9])
+	}
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := JSONLinesSink(&buf)
+	sink(Event{Type: EventToolStart, RunID: "run_1", Data: map[string]any{"tool": "lookup"}})
+	sink(Event{Type: EventRunEnd, RunID: "run_1"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if decoded["type"] != "tool_start" || decoded["run_id"] != "run_1" {
+		t.Errorf("unexpected event %v", decoded)
+	}
+}
@@ -139,11 +139,12 @@ func main() {
 	fmt.Printf("   Completion Tokens: %d\n", totalUsage.CompletionTokens)
 	fmt.Printf("   Total Tokens: %d\n", totalUsage.TotalTokens)
 
-	// Estimate cost (example rates - adjust based on actual OpenAI pricing)
-	promptCost := float64(totalUsage.PromptTokens) * 0.00001         // $0.01 per 1K tokens
-	completionCost := float64(totalUsage.CompletionTokens) * 0.00003 // $0.03 per 1K tokens
-	totalCost := promptCost + completionCost
-
+	// Estimate cost from the built-in pricing table
+	totalCost, err := totalUsage.Cost(simpleAgent.Model)
+	if err != nil {
+		fmt.Printf("   Estimated Cost: unavailable (%v)\n", err)
+		return
+	}
 	fmt.Printf("   Estimated Cost: $%.6f\n", totalCost)
 }
 
@@ -158,7 +159,7 @@ func printResults(label string, result *agents.Result) {
 
 	// Show step details
 	for i, step := range result.Steps {
-		fmt.Printf("   Step %d (%s): %d tool calls, %v\n",
-			i+1, step.AgentName, len(step.ToolCalls), step.Duration)
+		fmt.Printf("   Step %d (%s): %d tool calls, %d tokens, %v\n",
+			i+1, step.AgentName, len(step.ToolCalls), step.Usage.TotalTokens, step.Duration)
 	}
 }
@@ -3,7 +3,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -66,7 +65,7 @@ func main() {
 		// Get the final output which should be structured JSON
 		if result.FinalOutput != "" {
 			var reasoning MathReasoning
-			if err := json.Unmarshal([]byte(result.FinalOutput), &reasoning); err != nil {
+			if err := result.Unmarshal(&reasoning); err != nil {
 				log.Fatalf("Error parsing response: %v", err)
 			}
 
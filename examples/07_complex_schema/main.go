@@ -3,7 +3,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -95,7 +94,7 @@ john.smith@example.com. He enjoys hiking, photography, and cooking.`),
 		// Get the final output which should be structured JSON
 		if result.FinalOutput != "" {
 			var person Person
-			if err := json.Unmarshal([]byte(result.FinalOutput), &person); err != nil {
+			if err := result.Unmarshal(&person); err != nil {
 				log.Fatalf("Error parsing response: %v", err)
 			}
 
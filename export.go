@@ -0,0 +1,192 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// TranscriptVersion is the version of the portable transcript format
+// written by ExportTranscript.
+const TranscriptVersion = 1
+
+// TranscriptMessage is a message of a portable transcript. Only the fields
+// of its role are set.
+type TranscriptMessage struct {
+	// Role is "system", "developer", "user", "assistant" or "tool"
+	Role string `json:"role"`
+
+	// Content is the text of the message
+	Content string `json:"content,omitempty"`
+
+	// Refusal is the refusal of an assistant message
+	Refusal string `json:"refusal,omitempty"`
+
+	// ToolCalls are the tools an assistant message called
+	ToolCalls []TranscriptToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID is the call a tool message answers
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// TranscriptToolCall is a tool call of an assistant message in a portable
+// transcript.
+type TranscriptToolCall struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Arguments are the JSON-encoded arguments as the model produced them
+	Arguments string `json:"arguments"`
+}
+
+// transcriptDocument is the top-level object of a portable transcript.
+type transcriptDocument struct {
+	Version  int                 `json:"version"`
+	Messages []TranscriptMessage `json:"messages"`
+}
+
+// ExportTranscript serializes messages, such as Result.Messages or a
+// Conversation's history, to a portable JSON document that does not depend
+// on the openai-go types and can be archived or moved between backends:
+//
+//	{"version": 1, "messages": [
+//	  {"role": "user", "content": "Weather in Paris?"},
+//	  {"role": "assistant", "tool_calls": [{"id": "call_1", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}]},
+//	  {"role": "tool", "tool_call_id": "call_1", "content": "sunny"},
+//	  {"role": "assistant", "content": "It is sunny."}
+//	]}
+//
+// Text given as several content parts is joined with newlines. Messages
+// with images, audio or files and deprecated function messages cannot be
+// exported.
+func ExportTranscript(messages []openai.ChatCompletionMessageParamUnion) ([]byte, error) {
+	doc := transcriptDocument{Version: TranscriptVersion, Messages: make([]TranscriptMessage, 0, len(messages))}
+	for i, msg := range messages {
+		var m TranscriptMessage
+		switch {
+		case msg.OfSystem != nil:
+			c := msg.OfSystem.Content
+			m = TranscriptMessage{Role: "system", Content: textOrParts(c.OfString, c.OfArrayOfContentParts)}
+		case msg.OfDeveloper != nil:
+			c := msg.OfDeveloper.Content
+			m = TranscriptMessage{Role: "developer", Content: textOrParts(c.OfString, c.OfArrayOfContentParts)}
+		case msg.OfUser != nil:
+			for _, part := range msg.OfUser.Content.OfArrayOfContentParts {
+				if kind := nonTextPart(part); kind != "" {
+					return nil, fmt.Errorf("message %d: only text content can be exported, got %s", i, kind)
+				}
+			}
+			m = TranscriptMessage{Role: "user", Content: MessageText(msg)}
+		case msg.OfAssistant != nil:
+			m = exportAssistant(msg.OfAssistant)
+		case msg.OfTool != nil:
+			c := msg.OfTool.Content
+			m = TranscriptMessage{Role: "tool", Content: textOrParts(c.OfString, c.OfArrayOfContentParts), ToolCallID: msg.OfTool.ToolCallID}
+		default:
+			return nil, fmt.Errorf("message %d: unsupported message type", i)
+		}
+		doc.Messages = append(doc.Messages, m)
+	}
+	return json.Marshal(doc)
+}
+
+// nonTextPart returns the kind of a user content part that is not text.
+func nonTextPart(part openai.ChatCompletionContentPartUnionParam) string {
+	switch {
+	case part.OfImageURL != nil:
+		return "image"
+	case part.OfInputAudio != nil:
+		return "audio"
+	case part.OfFile != nil:
+		return "file"
+	}
+	return ""
+}
+
+// exportAssistant converts an assistant message, keeping text and refusal
+// apart.
+func exportAssistant(a *openai.ChatCompletionAssistantMessageParam) TranscriptMessage {
+	m := TranscriptMessage{Role: "assistant", Content: a.Content.OfString.Value, Refusal: a.Refusal.Value}
+	var texts, refusals []string
+	for _, part := range a.Content.OfArrayOfContentParts {
+		switch {
+		case part.OfText != nil:
+			texts = append(texts, part.OfText.Text)
+		case part.OfRefusal != nil:
+			refusals = append(refusals, part.OfRefusal.Refusal)
+		}
+	}
+	if len(texts) > 0 {
+		m.Content = strings.Join(texts, "\n")
+	}
+	if len(refusals) > 0 && m.Refusal == "" {
+		m.Refusal = strings.Join(refusals, "\n")
+	}
+	for _, call := range a.ToolCalls {
+		m.ToolCalls = append(m.ToolCalls, TranscriptToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return m
+}
+
+// ImportTranscript restores the messages of a transcript written by
+// ExportTranscript.
+func ImportTranscript(data []byte) ([]openai.ChatCompletionMessageParamUnion, error) {
+	var doc transcriptDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid transcript: %w", err)
+	}
+	if doc.Version != TranscriptVersion {
+		return nil, fmt.Errorf("unsupported transcript version %d", doc.Version)
+	}
+
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(doc.Messages))
+	for i, m := range doc.Messages {
+		if len(m.ToolCalls) > 0 && m.Role != "assistant" {
+			return nil, fmt.Errorf("message %d: %s message has tool calls", i, m.Role)
+		}
+		switch m.Role {
+		case "system":
+			messages = append(messages, openai.SystemMessage(m.Content))
+		case "developer":
+			messages = append(messages, openai.DeveloperMessage(m.Content))
+		case "user":
+			messages = append(messages, openai.UserMessage(m.Content))
+		case "assistant":
+			messages = append(messages, importAssistant(m))
+		case "tool":
+			if m.ToolCallID == "" {
+				return nil, fmt.Errorf("message %d: tool message has no tool_call_id", i)
+			}
+			messages = append(messages, openai.ToolMessage(m.Content, m.ToolCallID))
+		default:
+			return nil, fmt.Errorf("message %d: unknown role %q", i, m.Role)
+		}
+	}
+	return messages, nil
+}
+
+func importAssistant(m TranscriptMessage) openai.ChatCompletionMessageParamUnion {
+	var a openai.ChatCompletionAssistantMessageParam
+	if m.Content != "" {
+		a.Content.OfString = openai.String(m.Content)
+	}
+	if m.Refusal != "" {
+		a.Refusal = openai.String(m.Refusal)
+	}
+	for _, call := range m.ToolCalls {
+		a.ToolCalls = append(a.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+			ID: call.ID,
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{
+				Name:      call.Name,
+				Arguments: call.Arguments,
+			},
+		})
+	}
+	return openai.ChatCompletionMessageParamUnion{OfAssistant: &a}
+}
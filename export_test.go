@@ -0,0 +1,114 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestExportImportTranscript_RoundTrip(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}),
+		textCompletion("It is sunny in Paris."),
+	)
+
+	agent := NewAgent("Assistant")
+	agent.Instructions = "You report the weather."
+	agent.Tools = []Tool{
+		FunctionTool("get_weather", "Get the weather", nil, func(map[string]any, ContextVariables) (any, error) {
+			return "sunny", nil
+		}),
+	}
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.DeveloperMessage("Answer briefly."),
+		openai.UserMessage("Weather in Paris?"),
+	}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	history := append(result.Messages, openai.ChatCompletionMessageParamUnion{
+		OfAssistant: &openai.ChatCompletionAssistantMessageParam{Refusal: openai.String("I can't share that.")},
+	})
+
+	data, err := ExportTranscript(history)
+	if err != nil {
+		t.Fatalf("ExportTranscript failed: %v", err)
+	}
+	imported, err := ImportTranscript(data)
+	if err != nil {
+		t.Fatalf("ImportTranscript failed: %v", err)
+	}
+
+	want, _ := json.Marshal(history)
+	got, _ := json.Marshal(imported)
+	if string(got) != string(want) {
+		t.Errorf("round trip changed the messages:\nwant %s\ngot  %s", want, got)
+	}
+}
+
+func TestExportTranscript_Format(t *testing.T) {
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("Weather in Paris?"),
+		{OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+			ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+				ID:       "call_1",
+				Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}},
+		}},
+		openai.ToolMessage("sunny", "call_1"),
+		openai.AssistantMessage("It is sunny."),
+	}
+
+	data, err := ExportTranscript(messages)
+	if err != nil {
+		t.Fatalf("ExportTranscript failed: %v", err)
+	}
+	want := `{"version":1,"messages":[` +
+		`{"role":"user","content":"Weather in Paris?"},` +
+		`{"role":"assistant","tool_calls":[{"id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}]},` +
+		`{"role":"tool","content":"sunny","tool_call_id":"call_1"},` +
+		`{"role":"assistant","content":"It is sunny."}]}`
+	if string(data) != want {
+		t.Errorf("unexpected transcript:\nwant %s\ngot  %s", want, data)
+	}
+}
+
+func TestExportTranscript_Unsupported(t *testing.T) {
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+			openai.TextContentPart("What is this?"),
+			openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: "https://example.com/cat.png"}),
+		}),
+	}
+	_, err := ExportTranscript(messages)
+	if err == nil || !strings.Contains(err.Error(), "got image") {
+		t.Errorf("expected an error naming the image part, got %v", err)
+	}
+}
+
+func TestImportTranscript_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "invalid JSON", data: `{`, want: "invalid transcript"},
+		{name: "version", data: `{"version":2,"messages":[]}`, want: "unsupported transcript version 2"},
+		{name: "unknown role", data: `{"version":1,"messages":[{"role":"robot"}]}`, want: `unknown role "robot"`},
+		{name: "tool without call ID", data: `{"version":1,"messages":[{"role":"tool","content":"x"}]}`, want: "no tool_call_id"},
+		{name: "tool calls on user", data: `{"version":1,"messages":[{"role":"user","tool_calls":[{"id":"c","name":"n","arguments":"{}"}]}]}`, want: "user message has tool calls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportTranscript([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
+)
+
+// ExtractToolName is the name of the tool Extract forces the model to call
+const ExtractToolName = "extract"
+
+// Extract asks the model for structured data matching schema by forcing it
+// to call a single "extract" tool whose parameters are the schema, and
+// decodes the call's arguments into a T. Some models follow a function
+// schema more reliably than a response format.
+//
+// Extract makes one LLM call. The agent's tools, handoffs and response
+// format are not used, and the arguments are not sent back to the model.
+// Decoding failures are returned as *OutputValidationError.
+func Extract[T any](
+	ctx context.Context,
+	r *Runner,
+	agent *Agent,
+	messages []openai.ChatCompletionMessageParamUnion,
+	schema *jsonschema.Schema,
+) (T, error) {
+	var out T
+	args, err := r.extract(ctx, agent, messages, schema)
+	if err != nil {
+		return out, err
+	}
+	err = (&Result{}).decode(args, &out)
+	return out, err
+}
+
+// extract performs the forced tool call and returns its raw arguments.
+func (r *Runner) extract(
+	ctx context.Context,
+	agent *Agent,
+	messages []openai.ChatCompletionMessageParamUnion,
+	schema *jsonschema.Schema,
+) (string, error) {
+	if len(messages) == 0 {
+		return "", ErrNoMessages
+	}
+	if schema == nil {
+		return "", errors.New("extract requires a schema")
+	}
+	params, err := schema.ToMap()
+	if err != nil {
+		return "", fmt.Errorf("invalid schema: %w", err)
+	}
+
+	tool := openai.ChatCompletionToolParam{
+		Function: openai.FunctionDefinitionParam{
+			Name:        ExtractToolName,
+			Description: openai.String("Record the data extracted from the conversation."),
+			Parameters:  params,
+		},
+	}
+	config := DefaultRunConfig()
+	config.ToolChoice = ExtractToolName
+	req, err := r.prepareRequest(ctx, agent, config, []openai.ChatCompletionToolParam{tool}, messages)
+	if err != nil {
+		return "", err
+	}
+	req.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{}
+	req.ParallelToolCalls = openai.Bool(false)
+
+	completion, err := r.newCompletion(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("LLM call failed: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("LLM call failed: %w", ErrNoChoices)
+	}
+	for _, call := range completion.Choices[0].Message.ToolCalls {
+		if call.Function.Name == ExtractToolName {
+			return call.Function.Arguments, nil
+		}
+	}
+	return "", ErrNoExtraction
+}
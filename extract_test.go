@@ -0,0 +1,91 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
+)
+
+type extractedContact struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func contactSchema() *jsonschema.Schema {
+	return jsonschema.Object().
+		WithProperty("name", jsonschema.String()).
+		WithProperty("email", jsonschema.String()).
+		WithRequired("name", "email")
+}
+
+func TestExtract(t *testing.T) {
+	runner, mock := newMockRunner(t, toolCallCompletion(mockToolCall{
+		Name:      ExtractToolName,
+		Arguments: `{"name": "Ada", "email": "ada@example.com"}`,
+	}))
+	agent := NewAgent("Extractor")
+	agent.Tools = []Tool{{Name: "unused", Description: "Not sent"}}
+
+	contact, err := Extract[extractedContact](context.Background(), runner, agent,
+		[]openai.ChatCompletionMessageParamUnion{openai.UserMessage("Ada, ada@example.com")}, contactSchema())
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if contact != (extractedContact{Name: "Ada", Email: "ada@example.com"}) {
+		t.Errorf("unexpected contact %+v", contact)
+	}
+
+	reqs := mock.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected a single LLM call, got %d", len(reqs))
+	}
+	tools, _ := reqs[0]["tools"].([]any)
+	if len(tools) != 1 {
+		t.Fatalf("expected only the extract tool, got %v", reqs[0]["tools"])
+	}
+	choice, _ := reqs[0]["tool_choice"].(map[string]any)
+	if fn, _ := choice["function"].(map[string]any); fn["name"] != ExtractToolName {
+		t.Errorf("expected tool_choice forcing extract, got %v", reqs[0]["tool_choice"])
+	}
+	if reqs[0]["parallel_tool_calls"] != false {
+		t.Errorf("expected parallel tool calls disabled, got %v", reqs[0]["parallel_tool_calls"])
+	}
+}
+
+func TestExtract_Errors(t *testing.T) {
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+	tests := []struct {
+		name     string
+		response string
+		check    func(error) bool
+	}{
+		{
+			name:     "no tool call",
+			response: textCompletion("Ada"),
+			check:    func(err error) bool { return errors.Is(err, ErrNoExtraction) },
+		},
+		{
+			name:     "invalid arguments",
+			response: toolCallCompletion(mockToolCall{Name: ExtractToolName, Arguments: `{"name": 1}`}),
+			check: func(err error) bool {
+				var validationErr *OutputValidationError
+				return errors.As(err, &validationErr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, _ := newMockRunner(t, tt.response)
+			_, err := Extract[extractedContact](context.Background(), runner, NewAgent("Extractor"), messages, contactSchema())
+			if !tt.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/openai/openai-go"
+)
+
+// UploadFile uploads content through the Files API with the "user_data"
+// purpose and returns the file ID, which can be referenced with FileMessage.
+func (r *Runner) UploadFile(ctx context.Context, filename string, content io.Reader) (string, error) {
+	if r.Client == nil {
+		return "", ErrNoClient
+	}
+	file, err := r.Client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(content, filename, ""),
+		Purpose: openai.FilePurposeUserData,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file %s: %w", filename, err)
+	}
+	return file.ID, nil
+}
+
+// DeleteFile deletes a file previously uploaded with UploadFile.
+func (r *Runner) DeleteFile(ctx context.Context, fileID string) error {
+	if r.Client == nil {
+		return ErrNoClient
+	}
+	if _, err := r.Client.Files.Delete(ctx, fileID, requestOptions(ctx)...); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", fileID, err)
+	}
+	return nil
+}
+
+// FileMessage creates a user message with text followed by references to
+// uploaded files, so models that accept file inputs can read them.
+func FileMessage(text string, fileIDs ...string) openai.ChatCompletionMessageParamUnion {
+	parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(fileIDs)+1)
+	if text != "" {
+		parts = append(parts, openai.TextContentPart(text))
+	}
+	for _, id := range fileIDs {
+		parts = append(parts, openai.FileContentPart(openai.ChatCompletionContentPartFileFileParam{
+			FileID: openai.String(id),
+		}))
+	}
+	return openai.UserMessage(parts)
+}
+
+// deleteFiles removes the run's DeleteFiles; failures are logged rather than
+// returned so cleanup never masks the run's own result.
+func (r *Runner) deleteFiles(ctx context.Context, fileIDs []string) {
+	for _, id := range fileIDs {
+		if err := r.DeleteFile(ctx, id); err != nil {
+			LoggerFromContext(ctx).Warn("file cleanup failed", "file_id", id, "error", err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestFileMessage(t *testing.T) {
+	msg := FileMessage("Summarize this", "file-1", "file-2")
+
+	if msg.OfUser == nil {
+		t.Fatal("expected a user message")
+	}
+	parts := msg.OfUser.Content.OfArrayOfContentParts
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 content parts, got %d", len(parts))
+	}
+	if parts[0].OfText == nil || parts[0].OfText.Text != "Summarize this" {
+		t.Error("expected first part to be the text")
+	}
+	if parts[1].OfFile == nil || parts[1].OfFile.File.FileID.Value != "file-1" {
+		t.Error("expected second part to reference file-1")
+	}
+}
+
+func TestUploadAndDeleteFile(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		`{"id":"file-abc","object":"file","bytes":5,"created_at":0,"filename":"doc.txt","purpose":"user_data","status":"processed"}`,
+		textCompletion("It says hello."),
+		`{"id":"file-abc","object":"file","deleted":true}`,
+	)
+
+	ctx := context.Background()
+	fileID, err := runner.UploadFile(ctx, "doc.txt", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if fileID != "file-abc" {
+		t.Errorf("expected file-abc, got %s", fileID)
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{FileMessage("What does it say?", fileID)}
+	config := DefaultRunConfig()
+	config.DeleteFiles = []string{fileID}
+
+	if _, err := runner.Run(ctx, NewAgent("TestAgent"), messages, nil, config); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []string{"POST /files", "POST /chat/completions", "DELETE /files/file-abc"}
+	if fmt.Sprint(mock.Paths()) != fmt.Sprint(want) {
+		t.Errorf("expected requests %v, got %v", want, mock.Paths())
+	}
+}
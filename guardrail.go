@@ -0,0 +1,161 @@
+package agents
+
+import (
+	"context"
+
+	"github.com/openai/openai-go"
+)
+
+// ConversationGuardrail is a safety check that inspects the full
+// conversation history rather than a single message, so it can detect
+// patterns that build up over several turns, such as repeated probing.
+type ConversationGuardrail struct {
+	// Name identifies the guardrail in errors and logs.
+	Name string
+
+	// Func inspects the history that is about to be sent to the model,
+	// including tool results of the previous turn. Returning an error trips
+	// the guardrail and stops the run. Func must not modify the history.
+	// ctx carries the run deadline, so a guardrail calling a moderation
+	// service can give up before the run times out.
+	Func func(ctx context.Context, history []openai.ChatCompletionMessageParamUnion) error
+
+	// FuncWithVariables is an alternative to Func that also receives the
+	// run's context variables, for checks that depend on e.g. the user's
+	// role or tenant. It takes precedence over Func when both are set.
+	FuncWithVariables func(ctx context.Context, history []openai.ChatCompletionMessageParamUnion, vars ContextVariables) error
+
+	// FirstTurnOnly runs the guardrail only before the first turn, checking
+	// the conversation passed to Run. By default it runs before every turn.
+	FirstTurnOnly bool
+
+	// SafeResponse, if set, is the reply given when the guardrail trips:
+	// the run ends without an error, with SafeResponse as FinalOutput and
+	// StopReason set to StopReasonGuardrail. By default a tripped guardrail
+	// ends the run with a *GuardrailTrippedError.
+	SafeResponse string
+}
+
+// Check runs the guardrail's FuncWithVariables, or else its Func, on the
+// history. A guardrail without either passes.
+func (g ConversationGuardrail) Check(ctx context.Context, history []openai.ChatCompletionMessageParamUnion, vars ContextVariables) error {
+	switch {
+	case g.FuncWithVariables != nil:
+		return g.FuncWithVariables(ctx, history, vars)
+	case g.Func != nil:
+		return g.Func(ctx, history)
+	}
+	return nil
+}
+
+// WithSafeResponse returns a copy of the guardrail that answers with text
+// instead of failing the run when it trips.
+func (g ConversationGuardrail) WithSafeResponse(text string) ConversationGuardrail {
+	g.SafeResponse = text
+	return g
+}
+
+// checkConversationGuardrails runs the guardrails due for the given turn
+// in order and returns a *GuardrailTrippedError for the first that trips,
+// along with its safe response.
+func checkConversationGuardrails(
+	ctx context.Context,
+	agent *Agent,
+	guardrails []ConversationGuardrail,
+	history []openai.ChatCompletionMessageParamUnion,
+	vars ContextVariables,
+	turn int,
+) (string, error) {
+	for _, g := range guardrails {
+		if g.Func == nil && g.FuncWithVariables == nil || (g.FirstTurnOnly && turn > 1) {
+			continue
+		}
+		err := g.Check(ctx, history, vars)
+		data := map[string]any{"guardrail": g.Name, "tripped": err != nil}
+		if err != nil {
+			data["error"] = err.Error()
+		}
+		eventsFromContext(ctx).emit(EventGuardrail, agent, data)
+		if err != nil {
+			return g.SafeResponse, &GuardrailTrippedError{Guardrail: g.Name, Err: err}
+		}
+	}
+	return "", nil
+}
+
+// checkTurnGuardrails runs the run's conversation guardrails, then the
+// agent's, and returns the safe response and error of the first that trips.
+func checkTurnGuardrails(
+	ctx context.Context,
+	agent *Agent,
+	config *RunConfig,
+	history []openai.ChatCompletionMessageParamUnion,
+	vars ContextVariables,
+	turn int,
+) (string, error) {
+	for _, guardrails := range [][]ConversationGuardrail{config.ConversationGuardrails, agent.ConversationGuardrails} {
+		if safeResponse, err := checkConversationGuardrails(ctx, agent, guardrails, history, vars, turn); err != nil {
+			return safeResponse, err
+		}
+	}
+	return "", nil
+}
+
+// OutputGuardrail is a check on the final output of a run, e.g. that it
+// is valid JSON for the agent's response format or free of secrets.
+type OutputGuardrail struct {
+	// Name identifies the guardrail in errors and logs.
+	Name string
+
+	// Func inspects the final output. Returning an error trips the
+	// guardrail: the run returns its result with StopReasonGuardrail and a
+	// *GuardrailTrippedError. Refusals are not checked.
+	Func func(ctx context.Context, output string) error
+
+	// FuncWithVariables is an alternative to Func that also receives the
+	// run's context variables. It takes precedence over Func when both are set.
+	FuncWithVariables func(ctx context.Context, output string, vars ContextVariables) error
+}
+
+// Check runs the guardrail's FuncWithVariables, or else its Func, on the
+// output. A guardrail without either passes.
+func (g OutputGuardrail) Check(ctx context.Context, output string, vars ContextVariables) error {
+	switch {
+	case g.FuncWithVariables != nil:
+		return g.FuncWithVariables(ctx, output, vars)
+	case g.Func != nil:
+		return g.Func(ctx, output)
+	}
+	return nil
+}
+
+// checkOutputGuardrails runs the guardrails in order and returns a
+// *GuardrailTrippedError for the first that trips.
+func checkOutputGuardrails(ctx context.Context, agent *Agent, guardrails []OutputGuardrail, output string, vars ContextVariables) error {
+	for _, g := range guardrails {
+		if g.Func == nil && g.FuncWithVariables == nil {
+			continue
+		}
+		err := g.Check(ctx, output, vars)
+		data := map[string]any{"guardrail": g.Name, "tripped": err != nil}
+		if err != nil {
+			data["error"] = err.Error()
+		}
+		eventsFromContext(ctx).emit(EventGuardrail, agent, data)
+		if err != nil {
+			return &GuardrailTrippedError{Guardrail: g.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// checkFinalOutputGuardrails runs the run's output guardrails, then the
+// agent's, and returns the error of the first that trips.
+func checkFinalOutputGuardrails(ctx context.Context, agent *Agent, config *RunConfig, output string, vars ContextVariables) error {
+	for _, guardrails := range [][]OutputGuardrail{config.OutputGuardrails, agent.OutputGuardrails} {
+		if err := checkOutputGuardrails(ctx, agent, guardrails, output, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
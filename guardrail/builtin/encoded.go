@@ -0,0 +1,204 @@
+package builtin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// DefaultMinPayloadLength is the shortest encoded string the encoded payload
+// guardrail reports unless WithMinPayloadLength is given
+const DefaultMinPayloadLength = 64
+
+// maxPayloadSnippet bounds how much of a payload is quoted in errors
+const maxPayloadSnippet = 32
+
+// payloadCandidate matches runs of base64 (standard or URL-safe) and hex
+// characters, optionally prefixed with 0x or padded with '='.
+var payloadCandidate = regexp.MustCompile(`(?:0[xX])?[A-Za-z0-9+/_-]+={0,2}`)
+
+// EncodedPayloadError is returned, wrapped in an *agents.GuardrailTrippedError,
+// when the input contains an encoded payload.
+type EncodedPayloadError struct {
+	// Encoding is "base64" or "hex"
+	Encoding string
+
+	// Payload is the start of the encoded string
+	Payload string
+
+	// Decoded is the decoded payload if it is text, empty for binary data
+	Decoded string
+
+	// Err is the error of the guardrail that rejected the decoded text, when
+	// the payload was re-scanned (see WithDecodedScan)
+	Err error
+}
+
+func (e *EncodedPayloadError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s payload %q failed decoded scan: %v", e.Encoding, e.Payload, e.Err)
+	}
+	return fmt.Sprintf("input contains %s payload %q", e.Encoding, e.Payload)
+}
+
+func (e *EncodedPayloadError) Unwrap() error {
+	return e.Err
+}
+
+// EncodedPayloadOption configures NewEncodedPayloadGuardrail.
+type EncodedPayloadOption func(*encodedPayloadGuardrail)
+
+// WithMinPayloadLength sets the length from which base64 and hex strings
+// are treated as payloads. Shorter values catch more but also flag more
+// tokens and IDs.
+func WithMinPayloadLength(n int) EncodedPayloadOption {
+	return func(g *encodedPayloadGuardrail) {
+		g.minLength = n
+	}
+}
+
+// WithDecodedScan decodes payloads that contain text and checks them with
+// the given guardrails instead of rejecting them outright. The guardrail
+// then trips only if one of them rejects the decoded text, or if a payload
+// decodes to binary data that cannot be inspected.
+func WithDecodedScan(guardrails ...agents.ConversationGuardrail) EncodedPayloadOption {
+	return func(g *encodedPayloadGuardrail) {
+		g.scanners = append(g.scanners, guardrails...)
+	}
+}
+
+// NewEncodedPayloadGuardrail returns a guardrail that trips when the latest
+// user message contains a long base64 or hex string, a common way to
+// smuggle instructions past text-based checks. The guardrail runs before
+// the first turn of a run; use errors.As with *EncodedPayloadError to read
+// the encoding and decoded content.
+func NewEncodedPayloadGuardrail(opts ...EncodedPayloadOption) agents.ConversationGuardrail {
+	g := &encodedPayloadGuardrail{minLength: DefaultMinPayloadLength}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return agents.ConversationGuardrail{
+		Name:              "encoded_payload",
+		FuncWithVariables: g.check,
+		FirstTurnOnly:     true,
+	}
+}
+
+type encodedPayloadGuardrail struct {
+	minLength int
+	scanners  []agents.ConversationGuardrail
+}
+
+func (g *encodedPayloadGuardrail) check(ctx context.Context, history []openai.ChatCompletionMessageParamUnion, vars agents.ContextVariables) error {
+	input := agents.LastUserText(history)
+	for _, candidate := range payloadCandidate.FindAllString(input, -1) {
+		encoding, decoded, ok := decodePayload(candidate, g.minLength)
+		if !ok {
+			continue
+		}
+
+		payloadErr := &EncodedPayloadError{Encoding: encoding, Payload: snippet(candidate)}
+		if isText(decoded) {
+			payloadErr.Decoded = string(decoded)
+		}
+		if len(g.scanners) == 0 || payloadErr.Decoded == "" {
+			return payloadErr
+		}
+
+		rescan := []openai.ChatCompletionMessageParamUnion{openai.UserMessage(payloadErr.Decoded)}
+		for _, scanner := range g.scanners {
+			if err := scanner.Check(ctx, rescan, vars); err != nil {
+				payloadErr.Err = &agents.GuardrailTrippedError{Guardrail: scanner.Name, Err: err}
+				return payloadErr
+			}
+		}
+	}
+	return nil
+}
+
+// decodePayload decodes s as hex or base64 if it is at least minLength long.
+// Hex that decodes to binary is skipped, since it is almost always a hash,
+// key or ID, and so is unpadded base64 that does not mix upper case, lower
+// case and digits the way encoded data does, such as long identifiers.
+func decodePayload(s string, minLength int) (encoding string, decoded []byte, ok bool) {
+	if h, found := strings.CutPrefix(strings.ToLower(s), "0x"); found || isHex(s) {
+		if len(h) >= minLength && len(h)%2 == 0 {
+			if b, err := hex.DecodeString(h); err == nil && isText(b) {
+				return "hex", b, true
+			}
+		}
+		if found {
+			return "", nil, false
+		}
+	}
+
+	if len(s) < minLength || !looksEncoded(s) {
+		return "", nil, false
+	}
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return "base64", b, true
+		}
+	}
+	return "", nil, false
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// looksEncoded reports whether s is padded or mixes upper case letters,
+// lower case letters and digits.
+func looksEncoded(s string) bool {
+	if strings.HasSuffix(s, "=") {
+		return true
+	}
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// isText reports whether b is printable UTF-8 text.
+func isText(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func snippet(s string) string {
+	if len(s) > maxPayloadSnippet {
+		return s[:maxPayloadSnippet] + "..."
+	}
+	return s
+}
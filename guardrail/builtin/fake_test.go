@@ -0,0 +1,22 @@
+package builtin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// newFakeClient returns a client whose requests are served by handler on a
+// local test server, without retries.
+func newFakeClient(t *testing.T, handler http.HandlerFunc) *openai.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client := openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+	return &client
+}
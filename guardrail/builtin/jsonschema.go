@@ -0,0 +1,31 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
+)
+
+// NewJSONSchemaGuardrail returns an output guardrail that trips when the
+// final output is not JSON matching schema, checking types, enums,
+// required and additional properties, string lengths and patterns and
+// numeric bounds. Pass the schema of the agent's response format; for
+// jsonschema.JSONSchemaArray that is the wrapping object. Use errors.As
+// with *agents.SchemaViolationError to read the violations.
+func NewJSONSchemaGuardrail(schema *jsonschema.Schema) agents.OutputGuardrail {
+	return agents.OutputGuardrail{
+		Name: "json_schema",
+		Func: func(_ context.Context, output string) error {
+			violations, err := schema.CheckJSON([]byte(output))
+			if err != nil {
+				return fmt.Errorf("output is %w", err)
+			}
+			if len(violations) > 0 {
+				return &agents.SchemaViolationError{Violations: violations}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
+)
+
+func TestJSONSchemaGuardrail(t *testing.T) {
+	g := NewJSONSchemaGuardrail(jsonschema.Object().
+		WithProperty("answer", jsonschema.String()).
+		WithProperty("confidence", jsonschema.Number().WithMinimum(0).WithMaximum(1)).
+		WithRequired("answer", "confidence"))
+
+	tests := []struct {
+		name           string
+		output         string
+		wantViolations int
+		wantInvalid    bool
+	}{
+		{name: "valid", output: `{"answer": "42", "confidence": 0.9}`},
+		{name: "off schema", output: `{"answer": 42, "confidence": 2}`, wantViolations: 2},
+		{name: "missing field", output: `{"answer": "42"}`, wantViolations: 1},
+		{name: "malformed", output: `{"answer": "42"`, wantInvalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.Func(context.Background(), tt.output)
+			if tt.wantViolations == 0 && !tt.wantInvalid {
+				if err != nil {
+					t.Fatalf("expected output to pass, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected the guardrail to trip")
+			}
+			var schemaErr *agents.SchemaViolationError
+			if errors.As(err, &schemaErr) == tt.wantInvalid {
+				t.Fatalf("expected *SchemaViolationError only for parsable output, got %v", err)
+			}
+			if schemaErr != nil && len(schemaErr.Violations) != tt.wantViolations {
+				t.Errorf("expected %d violations, got %v", tt.wantViolations, schemaErr.Violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
+)
+
+// Verdicts of the judge model
+const (
+	VerdictPass = "pass"
+	VerdictFail = "fail"
+)
+
+// judgeInstructions frame the rubric for the judge model
+const judgeInstructions = `You are a guardrail that reviews user messages before they reach an assistant.
+Judge the message against the policy below. Answer "fail" if it violates the policy and "pass" otherwise, and give a one-sentence reason.
+
+Policy:
+`
+
+// verdictSchema is the structured output of the judge model
+var verdictSchema = jsonschema.Object().
+	WithProperty("verdict", jsonschema.String().WithEnum(VerdictPass, VerdictFail)).
+	WithProperty("reason", jsonschema.String().WithDescription("One sentence explaining the verdict")).
+	WithRequired("verdict", "reason")
+
+// JudgeError is returned, wrapped in an *agents.GuardrailTrippedError,
+// when the judge model finds that the input violates the rubric.
+type JudgeError struct {
+	// Reason is the judge model's explanation
+	Reason string
+}
+
+func (e *JudgeError) Error() string {
+	return "input judged off-policy: " + e.Reason
+}
+
+// NewLLMGuardrail returns a guardrail that asks model, typically a small
+// and cheap one, whether the latest user message complies with rubric,
+// e.g. "Only questions about our billing and invoices are allowed." The
+// model answers with a structured pass or fail verdict; a fail trips the
+// guardrail with a *JudgeError carrying its reason. Verdicts are not
+// cached, and the call shares the run's deadline. The guardrail runs
+// before the first turn of a run.
+func NewLLMGuardrail(client *openai.Client, model, rubric string) agents.ConversationGuardrail {
+	g := &llmGuardrail{client: client, model: model, rubric: rubric}
+	return agents.ConversationGuardrail{
+		Name:          "llm_judge",
+		Func:          g.check,
+		FirstTurnOnly: true,
+	}
+}
+
+type llmGuardrail struct {
+	client *openai.Client
+	model  string
+	rubric string
+}
+
+func (g *llmGuardrail) check(ctx context.Context, history []openai.ChatCompletionMessageParamUnion) error {
+	input := agents.LastUserText(history)
+	if input == "" {
+		return nil
+	}
+
+	schema, err := verdictSchema.ToMap()
+	if err != nil {
+		return fmt.Errorf("judge failed: %w", err)
+	}
+	completion, err := g.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModel(g.model),
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(judgeInstructions + g.rubric),
+			openai.UserMessage(input),
+		},
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				Type: "json_schema",
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   "verdict",
+					Schema: schema,
+					Strict: openai.Bool(true),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("judge failed: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return errors.New("judge failed: no choices")
+	}
+	message := completion.Choices[0].Message
+	if message.Refusal != "" {
+		return fmt.Errorf("judge failed: model refused: %s", message.Refusal)
+	}
+
+	var verdict struct {
+		Verdict string `json:"verdict"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(message.Content), &verdict); err != nil {
+		return fmt.Errorf("judge failed: invalid verdict: %w", err)
+	}
+	switch verdict.Verdict {
+	case VerdictPass:
+		return nil
+	case VerdictFail:
+		return &JudgeError{Reason: verdict.Reason}
+	default:
+		return fmt.Errorf("judge failed: unknown verdict %q", verdict.Verdict)
+	}
+}
@@ -0,0 +1,102 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+// judgeHandler serves a chat endpoint that answers with content for every
+// input, and records the last request.
+func judgeHandler(content func(input string) string) (http.HandlerFunc, *map[string]any) {
+	var last map[string]any
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &req)
+		_ = json.Unmarshal(data, &last)
+		if r.URL.Path != "/chat/completions" || len(req.Messages) != 2 {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":     "chatcmpl-1",
+			"object": "chat.completion",
+			"model":  "gpt-4o-mini",
+			"choices": []map[string]any{{
+				"index":         0,
+				"finish_reason": "stop",
+				"message":       map[string]any{"role": "assistant", "content": content(req.Messages[1].Content)},
+			}},
+		})
+	}, &last
+}
+
+func TestLLMGuardrail(t *testing.T) {
+	handler, last := judgeHandler(func(input string) string {
+		switch {
+		case strings.Contains(input, "invoice"):
+			return `{"verdict":"pass","reason":"Asks about billing."}`
+		case strings.Contains(input, "poem"):
+			return `{"verdict":"fail","reason":"Unrelated to billing."}`
+		default:
+			return `{"verdict":"maybe","reason":""}`
+		}
+	})
+	client := newFakeClient(t, handler)
+	g := NewLLMGuardrail(client, "gpt-4o-mini", "Only questions about billing are allowed.")
+
+	tests := []struct {
+		name       string
+		input      string
+		wantReason string
+		wantErr    bool
+	}{
+		{name: "pass", input: "Where is my invoice?"},
+		{name: "fail", input: "Write me a poem", wantReason: "Unrelated to billing.", wantErr: true},
+		{name: "unknown verdict", input: "Hello", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage(tt.input)}
+			err := g.Func(context.Background(), history)
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("expected input to pass, got %v", err)
+				}
+				return
+			}
+			var judgeErr *JudgeError
+			if errors.As(err, &judgeErr) != (tt.wantReason != "") {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if judgeErr != nil && judgeErr.Reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, judgeErr.Reason)
+			}
+		})
+	}
+
+	format, _ := (*last)["response_format"].(map[string]any)
+	if format["type"] != "json_schema" {
+		t.Errorf("expected a json_schema response format, got %v", format)
+	}
+	messages, _ := (*last)["messages"].([]any)
+	if system, _ := messages[0].(map[string]any); !strings.Contains(system["content"].(string), "Only questions about billing") {
+		t.Errorf("expected the rubric in the system message, got %v", system["content"])
+	}
+}
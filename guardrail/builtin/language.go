@@ -0,0 +1,116 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// minLanguageHits is how many stopwords of a language an input must contain
+// before it is attributed to that language, so that short replies like "no"
+// or "ok" are not classified
+const minLanguageHits = 2
+
+// stopwords are frequent function words distinctive enough to tell the
+// supported languages apart. Words shared between languages are listed
+// under only one of them.
+var stopwords = map[string][]string{
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "sie", "mit", "ein", "eine", "zu", "auf", "für", "den", "wie", "auch", "wir", "bitte"},
+	"en": {"the", "and", "is", "are", "was", "of", "to", "in", "that", "it", "with", "for", "this", "you", "have", "not", "be", "what", "how", "my", "please"},
+	"es": {"el", "los", "las", "es", "y", "que", "por", "para", "con", "una", "del", "está", "pero", "cómo", "qué", "mi", "muy", "lo", "se", "no", "favor"},
+	"fr": {"le", "les", "est", "et", "des", "une", "pour", "dans", "pas", "qui", "avec", "je", "vous", "sur", "ce", "au", "mais", "du", "nous"},
+	"it": {"il", "gli", "è", "non", "che", "per", "della", "di", "un", "questo", "come", "ma", "sono", "anche", "perché"},
+}
+
+// LanguageError is returned, wrapped in an *agents.GuardrailTrippedError,
+// when the input is written in a language that is not allowed.
+type LanguageError struct {
+	// Language is the detected language code, e.g. "es"
+	Language string
+
+	// Allowed lists the allowed language codes
+	Allowed []string
+}
+
+func (e *LanguageError) Error() string {
+	return fmt.Sprintf("input is in language %q, allowed: %s", e.Language, strings.Join(e.Allowed, ", "))
+}
+
+// LanguageOption configures NewLanguageGuardrail.
+type LanguageOption func(*languageGuardrail)
+
+// WithAllowedLanguages sets the ISO 639-1 codes of the languages the input
+// may be written in. Without it, only English ("en") is allowed.
+func WithAllowedLanguages(codes ...string) LanguageOption {
+	return func(g *languageGuardrail) {
+		g.allowed = append(g.allowed, codes...)
+	}
+}
+
+// NewLanguageGuardrail returns a guardrail that trips when the latest user
+// message is written in a language that is not allowed. The language is
+// detected locally from common stopwords, which covers English ("en"),
+// Spanish ("es"), French ("fr"), German ("de") and Italian ("it"); inputs
+// too short or too mixed to classify pass. The guardrail runs before the
+// first turn of a run; use errors.As with *LanguageError to read the
+// detected language.
+func NewLanguageGuardrail(opts ...LanguageOption) agents.ConversationGuardrail {
+	g := &languageGuardrail{}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if len(g.allowed) == 0 {
+		g.allowed = []string{"en"}
+	}
+	return agents.ConversationGuardrail{
+		Name:          "language",
+		Func:          g.check,
+		FirstTurnOnly: true,
+	}
+}
+
+type languageGuardrail struct {
+	allowed []string
+}
+
+func (g *languageGuardrail) check(_ context.Context, history []openai.ChatCompletionMessageParamUnion) error {
+	language := detectLanguage(agents.LastUserText(history))
+	if language == "" || slices.Contains(g.allowed, language) {
+		return nil
+	}
+	return &LanguageError{Language: language, Allowed: g.allowed}
+}
+
+// detectLanguage returns the code of the language with the most stopwords
+// in text, or "" if none reaches minLanguageHits or two languages tie.
+func detectLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	best, bestHits, tied := "", 0, false
+	for language, list := range stopwords {
+		hits := 0
+		for _, word := range words {
+			if slices.Contains(list, word) {
+				hits++
+			}
+		}
+		switch {
+		case hits > bestHits:
+			best, bestHits, tied = language, hits, false
+		case hits == bestHits:
+			tied = true
+		}
+	}
+	if bestHits < minLanguageHits || tied {
+		return ""
+	}
+	return best
+}
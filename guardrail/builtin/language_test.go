@@ -0,0 +1,81 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestLanguageGuardrail(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		opts         []LanguageOption
+		wantLanguage string
+	}{
+		{
+			name:  "english",
+			input: "What is the status of my order? I paid for it with a credit card last week.",
+		},
+		{
+			name:         "spanish",
+			input:        "¿Cuál es el estado de mi pedido? Lo pagué con una tarjeta de crédito la semana pasada.",
+			wantLanguage: "es",
+		},
+		{
+			name:  "spanish allowed",
+			input: "¿Cuál es el estado de mi pedido? Lo pagué con una tarjeta de crédito la semana pasada.",
+			opts:  []LanguageOption{WithAllowedLanguages("en", "es")},
+		},
+		{
+			name:         "english not allowed",
+			input:        "What is the status of my order? I paid for it with a credit card last week.",
+			opts:         []LanguageOption{WithAllowedLanguages("es")},
+			wantLanguage: "en",
+		},
+		{
+			name:  "too short to classify",
+			input: "no",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewLanguageGuardrail(tt.opts...)
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage(tt.input)}
+			err := g.Func(context.Background(), history)
+
+			if tt.wantLanguage == "" {
+				if err != nil {
+					t.Fatalf("expected input to pass, got %v", err)
+				}
+				return
+			}
+			var langErr *LanguageError
+			if !errors.As(err, &langErr) {
+				t.Fatalf("expected *LanguageError, got %v", err)
+			}
+			if langErr.Language != tt.wantLanguage {
+				t.Errorf("expected language %q, got %q", tt.wantLanguage, langErr.Language)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"The quick brown fox jumps over the lazy dog and runs to the forest.": "en",
+		"El perro come la comida que está en la mesa y se va.":                "es",
+		"Je ne sais pas où est la gare, mais vous pouvez demander.":           "fr",
+		"Ich weiß nicht, wo der Bahnhof ist, aber sie können fragen.":         "de",
+		"Non so dove sia la stazione, ma il treno è in ritardo.":              "it",
+		"12345 !!!": "",
+	}
+	for in, want := range tests {
+		if got := detectLanguage(in); got != want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// ModerationModel is the model used to classify inputs
+const ModerationModel = openai.ModerationModelOmniModerationLatest
+
+// ModerationError is returned, wrapped in an *agents.GuardrailTrippedError,
+// when the input is flagged by the moderation endpoint.
+type ModerationError struct {
+	// Categories maps each offending category, e.g. "harassment" or
+	// "self-harm/intent", to its score
+	Categories map[string]float64
+}
+
+func (e *ModerationError) Error() string {
+	names := make([]string, 0, len(e.Categories))
+	for name := range e.Categories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s (%.2f)", name, e.Categories[name])
+	}
+	return "input flagged for " + strings.Join(names, ", ")
+}
+
+// ModerationOption configures NewModerationGuardrail.
+type ModerationOption func(*moderationGuardrail)
+
+// WithModerationCategories limits the guardrail to the given categories,
+// using the names of the moderation API such as "hate" or
+// "violence/graphic". Other categories never trip it.
+func WithModerationCategories(categories ...string) ModerationOption {
+	return func(g *moderationGuardrail) {
+		g.categories = append(g.categories, categories...)
+	}
+}
+
+// WithModerationThreshold trips the guardrail when a category's score
+// exceeds threshold (0.0 to 1.0), instead of relying on the categories the
+// moderation API flags itself. Lower values are stricter.
+func WithModerationThreshold(threshold float64) ModerationOption {
+	return func(g *moderationGuardrail) {
+		g.threshold = &threshold
+	}
+}
+
+// NewModerationGuardrail returns a guardrail that classifies the latest
+// user message with the OpenAI moderation endpoint and trips when it is
+// flagged for harassment, hate, violence or any other moderation category.
+// The guardrail runs before the first turn of a run; use errors.As with
+// *ModerationError to read the offending categories and their scores.
+func NewModerationGuardrail(client *openai.Client, opts ...ModerationOption) agents.ConversationGuardrail {
+	g := &moderationGuardrail{client: client}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return agents.ConversationGuardrail{
+		Name:          "moderation",
+		Func:          g.check,
+		FirstTurnOnly: true,
+	}
+}
+
+type moderationGuardrail struct {
+	client     *openai.Client
+	categories []string
+	threshold  *float64
+}
+
+func (g *moderationGuardrail) check(ctx context.Context, history []openai.ChatCompletionMessageParamUnion) error {
+	input := agents.LastUserText(history)
+	if input == "" {
+		return nil
+	}
+
+	resp, err := g.client.Moderations.New(ctx, openai.ModerationNewParams{
+		Model: ModerationModel,
+		Input: openai.ModerationNewParamsInputUnion{OfString: openai.String(input)},
+	})
+	if err != nil {
+		return fmt.Errorf("moderation failed: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		return errors.New("moderation failed: no results")
+	}
+
+	// The category names are only available as JSON keys
+	result := resp.Results[0]
+	var flagged map[string]bool
+	var scores map[string]float64
+	if err := json.Unmarshal([]byte(result.Categories.RawJSON()), &flagged); err != nil {
+		return fmt.Errorf("moderation failed: invalid categories: %w", err)
+	}
+	if err := json.Unmarshal([]byte(result.CategoryScores.RawJSON()), &scores); err != nil {
+		return fmt.Errorf("moderation failed: invalid category scores: %w", err)
+	}
+
+	offending := make(map[string]float64)
+	for name, score := range scores {
+		if len(g.categories) > 0 && !slices.Contains(g.categories, name) {
+			continue
+		}
+		if g.threshold != nil && score > *g.threshold || g.threshold == nil && flagged[name] {
+			offending[name] = score
+		}
+	}
+	if len(offending) > 0 {
+		return &ModerationError{Categories: offending}
+	}
+	return nil
+}
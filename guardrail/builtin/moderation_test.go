@@ -0,0 +1,114 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+// moderationHandler serves a moderation endpoint that flags "harassment"
+// with a score of 0.9 and scores "violence" at 0.4.
+func moderationHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model string `json:"model"`
+		Input string `json:"input"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	if r.URL.Path != "/moderations" || req.Model != string(ModerationModel) {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+
+	flagged := req.Input == "you are worthless"
+	scores := map[string]float64{"harassment": 0.01, "violence": 0.01, "hate": 0.01}
+	if flagged {
+		scores["harassment"] = 0.9
+		scores["violence"] = 0.4
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":    "modr-1",
+		"model": ModerationModel,
+		"results": []map[string]any{{
+			"flagged":         flagged,
+			"categories":      map[string]bool{"harassment": flagged, "violence": false, "hate": false},
+			"category_scores": scores,
+		}},
+	})
+}
+
+func TestModerationGuardrail(t *testing.T) {
+	client := newFakeClient(t, moderationHandler)
+
+	tests := []struct {
+		name  string
+		input string
+		opts  []ModerationOption
+		want  map[string]float64
+	}{
+		{
+			name:  "clean input",
+			input: "what's the weather like",
+		},
+		{
+			name:  "flagged by the API",
+			input: "you are worthless",
+			want:  map[string]float64{"harassment": 0.9},
+		},
+		{
+			name:  "threshold",
+			input: "you are worthless",
+			opts:  []ModerationOption{WithModerationThreshold(0.3)},
+			want:  map[string]float64{"harassment": 0.9, "violence": 0.4},
+		},
+		{
+			name:  "categories",
+			input: "you are worthless",
+			opts:  []ModerationOption{WithModerationCategories("violence", "hate")},
+		},
+		{
+			name:  "categories and threshold",
+			input: "you are worthless",
+			opts:  []ModerationOption{WithModerationCategories("violence"), WithModerationThreshold(0.3)},
+			want:  map[string]float64{"violence": 0.4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewModerationGuardrail(client, tt.opts...)
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage(tt.input)}
+			err := g.Func(context.Background(), history)
+
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("expected input to pass, got %v", err)
+				}
+				return
+			}
+			var modErr *ModerationError
+			if !errors.As(err, &modErr) {
+				t.Fatalf("expected *ModerationError, got %v", err)
+			}
+			if len(modErr.Categories) != len(tt.want) {
+				t.Fatalf("expected categories %v, got %v", tt.want, modErr.Categories)
+			}
+			for name, score := range tt.want {
+				if modErr.Categories[name] != score {
+					t.Errorf("expected %s score %v, got %v", name, score, modErr.Categories[name])
+				}
+			}
+		})
+	}
+}
+
+func TestModerationError_Error(t *testing.T) {
+	err := &ModerationError{Categories: map[string]float64{"violence": 0.4, "harassment": 0.9}}
+	if got, want := err.Error(), "input flagged for harassment (0.90), violence (0.40)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
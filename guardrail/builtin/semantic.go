@@ -0,0 +1,143 @@
+// Package builtin provides ready-made guardrails for common safety checks.
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// EmbeddingModel is the model used to embed inputs and reference examples
+const EmbeddingModel = openai.EmbeddingModelTextEmbedding3Small
+
+// SemanticMatchError is returned, wrapped in an *agents.GuardrailTrippedError,
+// when the input is too similar to a forbidden reference example.
+type SemanticMatchError struct {
+	// Example is the reference example closest to the input
+	Example string
+
+	// Score is the cosine similarity between the input and Example
+	Score float64
+}
+
+func (e *SemanticMatchError) Error() string {
+	return fmt.Sprintf("input matches forbidden example %q (similarity %.3f)", e.Example, e.Score)
+}
+
+// NewSemanticGuardrail returns a guardrail that embeds the latest user
+// message and trips when its cosine similarity to any of the reference
+// examples exceeds threshold. Unlike keyword checks it also catches
+// paraphrases of the forbidden topics.
+//
+// The reference examples are embedded on first use and cached, so each
+// later check costs a single embeddings call. The guardrail runs before the
+// first turn of a run; use errors.As with *SemanticMatchError to read the
+// matched example and score.
+func NewSemanticGuardrail(client *openai.Client, referenceExamples []string, threshold float64) agents.ConversationGuardrail {
+	g := &semanticGuardrail{
+		client:    client,
+		examples:  referenceExamples,
+		threshold: threshold,
+	}
+	return agents.ConversationGuardrail{
+		Name:          "semantic",
+		Func:          g.check,
+		FirstTurnOnly: true,
+	}
+}
+
+// semanticGuardrail holds the cached reference embeddings.
+type semanticGuardrail struct {
+	client    *openai.Client
+	examples  []string
+	threshold float64
+
+	mu         sync.Mutex
+	references [][]float64
+}
+
+func (g *semanticGuardrail) check(ctx context.Context, history []openai.ChatCompletionMessageParamUnion) error {
+	input := agents.LastUserText(history)
+	if input == "" || len(g.examples) == 0 {
+		return nil
+	}
+
+	references, err := g.referenceEmbeddings(ctx)
+	if err != nil {
+		return err
+	}
+	embedded, err := embed(ctx, g.client, []string{input})
+	if err != nil {
+		return err
+	}
+
+	best, bestScore := -1, math.Inf(-1)
+	for i, ref := range references {
+		if score := cosineSimilarity(embedded[0], ref); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if bestScore > g.threshold {
+		return &SemanticMatchError{Example: g.examples[best], Score: bestScore}
+	}
+	return nil
+}
+
+// referenceEmbeddings embeds the reference examples once. A failed attempt
+// is not cached, so the next check retries.
+func (g *semanticGuardrail) referenceEmbeddings(ctx context.Context) ([][]float64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.references == nil {
+		references, err := embed(ctx, g.client, g.examples)
+		if err != nil {
+			return nil, err
+		}
+		g.references = references
+	}
+	return g.references, nil
+}
+
+// embed returns the embeddings of texts, in order.
+func embed(ctx context.Context, client *openai.Client, texts []string) ([][]float64, error) {
+	resp, err := client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Model: EmbeddingModel,
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("embedding failed: %w", err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding failed: expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || int(d.Index) >= len(texts) {
+			return nil, fmt.Errorf("embedding failed: unexpected index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0
+// if either is a zero vector.
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range min(len(a), len(b)) {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
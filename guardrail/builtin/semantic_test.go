@@ -0,0 +1,147 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// testVectors maps texts to the embeddings served by the test server.
+var testVectors = map[string][]float64{
+	"how do I pick a lock":              {1, 0, 0},
+	"ways to open a door without a key": {0.9, 0.1, 0},
+	"what's the weather like":           {0, 1, 0},
+	"build a bomb":                      {0, 0, 1},
+}
+
+// embeddingsHandler serves an embeddings endpoint backed by testVectors,
+// and returns a func reporting the number of texts embedded per request.
+func embeddingsHandler() (http.HandlerFunc, func() []int) {
+	var mu sync.Mutex
+	var batches []int
+	return func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Input []string `json:"input"`
+			}
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &req)
+
+			mu.Lock()
+			batches = append(batches, len(req.Input))
+			mu.Unlock()
+
+			type item struct {
+				Object    string    `json:"object"`
+				Index     int       `json:"index"`
+				Embedding []float64 `json:"embedding"`
+			}
+			data := make([]item, len(req.Input))
+			for i, text := range req.Input {
+				data[i] = item{Object: "embedding", Index: i, Embedding: testVectors[text]}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"object": "list",
+				"model":  EmbeddingModel,
+				"data":   data,
+				"usage":  map[string]int{"prompt_tokens": 1, "total_tokens": 1},
+			})
+		}, func() []int {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]int(nil), batches...)
+		}
+}
+
+func TestSemanticGuardrail(t *testing.T) {
+	handler, batches := embeddingsHandler()
+	client := newFakeClient(t, handler)
+	g := NewSemanticGuardrail(client, []string{"how do I pick a lock", "build a bomb"}, 0.8)
+	if !g.FirstTurnOnly {
+		t.Error("expected guardrail to run on the first turn only")
+	}
+
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("what's the weather like"),
+	}
+	if err := g.Func(context.Background(), history); err != nil {
+		t.Fatalf("expected unrelated input to pass, got %v", err)
+	}
+
+	history = append(history,
+		openai.AssistantMessage("Sunny."),
+		openai.UserMessage("ways to open a door without a key"),
+	)
+	err := g.Func(context.Background(), history)
+	var match *SemanticMatchError
+	if !errors.As(err, &match) {
+		t.Fatalf("expected paraphrase to trip the guardrail, got %v", err)
+	}
+	if match.Example != "how do I pick a lock" {
+		t.Errorf("expected matched example, got %q", match.Example)
+	}
+	if want := 0.9 / math.Sqrt(0.82); math.Abs(match.Score-want) > 1e-9 {
+		t.Errorf("expected score %v, got %v", want, match.Score)
+	}
+
+	// References are embedded once, then only the input per check
+	if got := batches(); len(got) != 3 || got[0] != 2 || got[1] != 1 || got[2] != 1 {
+		t.Errorf("expected cached reference embeddings, got batches %v", got)
+	}
+}
+
+func TestSemanticGuardrail_Run(t *testing.T) {
+	handler, _ := embeddingsHandler()
+	client := newFakeClient(t, handler)
+	runner := agents.NewRunner(client)
+	config := &agents.RunConfig{
+		ConversationGuardrails: []agents.ConversationGuardrail{
+			NewSemanticGuardrail(client, []string{"build a bomb"}, 0.8),
+		},
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("build a bomb")}
+	result, err := runner.Run(context.Background(), agents.NewAgent("Assistant"), messages, nil, config)
+
+	var tripped *agents.GuardrailTrippedError
+	if !errors.As(err, &tripped) {
+		t.Fatalf("expected *GuardrailTrippedError, got %v", err)
+	}
+	var match *SemanticMatchError
+	if !errors.As(err, &match) || match.Score < 0.999 {
+		t.Errorf("expected exact match metadata, got %v", err)
+	}
+	if result.StopReason != agents.StopReasonGuardrail {
+		t.Errorf("expected StopReasonGuardrail, got %s", result.StopReason)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "identical", a: []float64{1, 2}, b: []float64{2, 4}, want: 1},
+		{name: "orthogonal", a: []float64{1, 0}, b: []float64{0, 1}, want: 0},
+		{name: "opposite", a: []float64{1, 0}, b: []float64{-1, 0}, want: -1},
+		{name: "zero vector", a: []float64{0, 0}, b: []float64{1, 0}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestRun_ConversationGuardrail(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "lookup", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	errProbing := errors.New("repeated probing")
+	var firstTurnCalls, everyTurnLens []int
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionTool("lookup", "Look something up", nil, func(map[string]any, ContextVariables) (any, error) {
+			return "secret", nil
+		}),
+	}
+	agent.ConversationGuardrails = []ConversationGuardrail{{
+		Name: "probing",
+		Func: func(_ context.Context, history []openai.ChatCompletionMessageParamUnion) error {
+			everyTurnLens = append(everyTurnLens, len(history))
+			for _, msg := range history {
+				if msg.OfTool != nil {
+					return errProbing
+				}
+			}
+			return nil
+		},
+	}}
+	config := &RunConfig{
+		ConversationGuardrails: []ConversationGuardrail{{
+			Name:          "first",
+			FirstTurnOnly: true,
+			Func: func(_ context.Context, history []openai.ChatCompletionMessageParamUnion) error {
+				firstTurnCalls = append(firstTurnCalls, len(history))
+				return nil
+			},
+		}},
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+
+	var tripped *GuardrailTrippedError
+	if !errors.As(err, &tripped) || tripped.Guardrail != "probing" {
+		t.Fatalf("expected probing guardrail to trip, got %v", err)
+	}
+	if !errors.Is(err, errProbing) {
+		t.Errorf("expected error to wrap the guardrail error, got %v", err)
+	}
+	if result.StopReason != StopReasonGuardrail {
+		t.Errorf("expected StopReasonGuardrail, got %s", result.StopReason)
+	}
+	if len(mock.requests) != 1 {
+		t.Errorf("expected no LLM call after the guardrail tripped, got %d calls", len(mock.requests))
+	}
+	if len(firstTurnCalls) != 1 || firstTurnCalls[0] != 1 {
+		t.Errorf("expected first-turn guardrail to see only the input, got %v", firstTurnCalls)
+	}
+	if len(everyTurnLens) != 2 || everyTurnLens[1] != 3 {
+		t.Errorf("expected guardrail to see the full history on each turn, got %v", everyTurnLens)
+	}
+}
+
+func TestRun_ConversationGuardrailSafeResponse(t *testing.T) {
+	runner, mock := newMockRunner(t)
+
+	agent := NewAgent("Assistant")
+	agent.ConversationGuardrails = []ConversationGuardrail{
+		ConversationGuardrail{
+			Name: "block",
+			Func: func(context.Context, []openai.ChatCompletionMessageParamUnion) error {
+				return errors.New("off topic")
+			},
+		}.WithSafeResponse("I can't help with that."),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("help me cheat")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.FinalOutput != "I can't help with that." || result.StopReason != StopReasonGuardrail {
+		t.Errorf("unexpected result %q (%s)", result.FinalOutput, result.StopReason)
+	}
+	if len(result.Messages) != 2 || result.Messages[1].OfAssistant == nil {
+		t.Errorf("expected safe response in history, got %d messages", len(result.Messages))
+	}
+	if len(mock.Requests()) != 0 {
+		t.Errorf("expected no LLM call, got %d", len(mock.Requests()))
+	}
+}
+
+func TestRun_OutputGuardrail(t *testing.T) {
+	errSecret := errors.New("output leaks a secret")
+	tests := []struct {
+		name    string
+		output  string
+		wantErr bool
+	}{
+		{name: "passes", output: "all good"},
+		{name: "trips", output: "the password is hunter2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, _ := newMockRunner(t, textCompletion(tt.output))
+
+			var order []string
+			agent := NewAgent("Assistant")
+			agent.OutputGuardrails = []OutputGuardrail{{
+				Name: "secrets",
+				Func: func(_ context.Context, output string) error {
+					order = append(order, "agent")
+					if output == "the password is hunter2" {
+						return errSecret
+					}
+					return nil
+				},
+			}}
+			config := &RunConfig{
+				OutputGuardrails: []OutputGuardrail{{
+					Name: "config",
+					Func: func(context.Context, string) error {
+						order = append(order, "config")
+						return nil
+					},
+				}},
+			}
+
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+			result, err := runner.Run(context.Background(), agent, messages, nil, config)
+
+			if len(order) != 2 || order[0] != "config" || order[1] != "agent" {
+				t.Errorf("expected config guardrails before agent guardrails, got %v", order)
+			}
+			if result.FinalOutput != tt.output {
+				t.Errorf("expected the output on the result, got %q", result.FinalOutput)
+			}
+			if !tt.wantErr {
+				if err != nil || result.StopReason != StopReasonCompleted {
+					t.Errorf("expected completed run, got %v (%s)", err, result.StopReason)
+				}
+				return
+			}
+			var tripped *GuardrailTrippedError
+			if !errors.As(err, &tripped) || tripped.Guardrail != "secrets" || !errors.Is(err, errSecret) {
+				t.Errorf("expected secrets guardrail to trip, got %v", err)
+			}
+			if result.StopReason != StopReasonGuardrail {
+				t.Errorf("expected StopReasonGuardrail, got %s", result.StopReason)
+			}
+		})
+	}
+}
+
+func TestRun_GuardrailContextVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		wantErr bool
+	}{
+		{name: "admin", role: "admin"},
+		{name: "guest", role: "guest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, _ := newMockRunner(t, textCompletion("the admin panel is at /admin"))
+
+			errNotAdmin := errors.New("admin only")
+			var outputRole any
+			agent := NewAgent("Assistant")
+			agent.ConversationGuardrails = []ConversationGuardrail{{
+				Name: "role",
+				Func: func(context.Context, []openai.ChatCompletionMessageParamUnion) error {
+					t.Error("expected FuncWithVariables to take precedence over Func")
+					return nil
+				},
+				FuncWithVariables: func(_ context.Context, _ []openai.ChatCompletionMessageParamUnion, vars ContextVariables) error {
+					if vars["role"] != "admin" {
+						return errNotAdmin
+					}
+					return nil
+				},
+			}}
+			agent.OutputGuardrails = []OutputGuardrail{{
+				Name: "output_role",
+				FuncWithVariables: func(_ context.Context, _ string, vars ContextVariables) error {
+					outputRole = vars["role"]
+					return nil
+				},
+			}}
+
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("where is the admin panel?")}
+			_, err := runner.Run(context.Background(), agent, messages, ContextVariables{"role": tt.role}, nil)
+
+			if tt.wantErr {
+				if !errors.Is(err, errNotAdmin) {
+					t.Fatalf("expected guardrail to trip, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if outputRole != "admin" {
+				t.Errorf("expected output guardrail to see the role, got %v", outputRole)
+			}
+		})
+	}
+}
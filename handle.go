@@ -0,0 +1,87 @@
+package agents
+
+import (
+	"context"
+
+	"github.com/openai/openai-go"
+)
+
+// RunHandle is a run started in the background with Runner.RunWithID.
+type RunHandle struct {
+	// ID is the run ID; it matches Result.RunID and RunIDFromContext.
+	ID string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+// Cancel stops the run. A pending LLM call is aborted; otherwise the run
+// stops at its next turn boundary. Either way it ends with context.Canceled
+// and a partial Result with StopReasonCancelled.
+func (h *RunHandle) Cancel() {
+	h.cancel()
+}
+
+// Done returns a channel that is closed when the run finishes.
+func (h *RunHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the run finishes and returns its result.
+func (h *RunHandle) Wait() (*Result, error) {
+	<-h.done
+	return h.result, h.err
+}
+
+type presetRunIDKey struct{}
+
+// RunWithID starts the agent loop in the background and returns a handle to
+// it. The run can be cancelled through the handle or, from anywhere with
+// access to the runner, with Runner.Cancel and the handle's ID.
+func (r *Runner) RunWithID(
+	ctx context.Context,
+	agent *Agent,
+	messages []openai.ChatCompletionMessageParamUnion,
+	contextParams ContextVariables,
+	config *RunConfig,
+) *RunHandle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &RunHandle{
+		ID:     newRunID(),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	ctx = context.WithValue(ctx, presetRunIDKey{}, h.ID)
+
+	r.active.Store(h.ID, h)
+	go func() {
+		defer close(h.done)
+		defer r.active.Delete(h.ID)
+		defer cancel()
+		h.result, h.err = r.Run(ctx, agent, messages, contextParams, config)
+	}()
+	return h
+}
+
+// Cancel cancels the in-flight run with the given ID started by RunWithID.
+// It reports whether such a run was found.
+func (r *Runner) Cancel(runID string) bool {
+	v, ok := r.active.Load(runID)
+	if !ok {
+		return false
+	}
+	v.(*RunHandle).Cancel()
+	return true
+}
+
+// claimRunID returns the ID preassigned by RunWithID, or a new one. The
+// preassigned ID is removed from the returned context so nested runs
+// started from tools get their own IDs.
+func claimRunID(ctx context.Context) (context.Context, string) {
+	if id, ok := ctx.Value(presetRunIDKey{}).(string); ok {
+		return context.WithValue(ctx, presetRunIDKey{}, nil), id
+	}
+	return ctx, newRunID()
+}
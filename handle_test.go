@@ -0,0 +1,129 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+func TestRunWithID_Completes(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "nested", Arguments: `{}`}),
+		textCompletion("inner"),
+		textCompletion("outer"),
+	)
+
+	// A run started from a tool must not inherit the handle's ID
+	var nestedID string
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionToolWithContext("nested", "Run a nested agent", nil,
+			func(ctx context.Context, _ map[string]any, _ ContextVariables) (any, error) {
+				msgs := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("inner")}
+				res, err := runner.Run(ctx, NewAgent("Inner"), msgs, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+				nestedID = res.RunID
+				return res.FinalOutput, nil
+			}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	h := runner.RunWithID(context.Background(), agent, messages, nil, nil)
+	result, err := h.Wait()
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.RunID != h.ID {
+		t.Errorf("expected result RunID %s, got %s", h.ID, result.RunID)
+	}
+	if nestedID == "" || nestedID == h.ID {
+		t.Errorf("expected nested run to get its own ID, got %q", nestedID)
+	}
+	if runner.Cancel(h.ID) {
+		t.Error("expected finished run to be removed from the registry")
+	}
+}
+
+func TestRunWithID_CancelByID(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "block", Arguments: `{}`}),
+	)
+
+	started := make(chan struct{})
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionToolWithContext("block", "Block until cancelled", nil,
+			func(ctx context.Context, _ map[string]any, _ ContextVariables) (any, error) {
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	h := runner.RunWithID(context.Background(), agent, messages, nil, nil)
+	<-started
+
+	if !runner.Cancel(h.ID) {
+		t.Fatal("expected in-flight run to be found")
+	}
+
+	select {
+	case <-h.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop after Cancel")
+	}
+	result, err := h.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if result.StopReason != StopReasonCancelled {
+		t.Errorf("expected StopReasonCancelled, got %s", result.StopReason)
+	}
+}
+
+func TestRunWithID_CancelDuringLLMCall(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+	client := openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test-key"), option.WithMaxRetries(0))
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	h := NewRunner(&client).RunWithID(context.Background(), NewAgent("Assistant"), messages, nil, nil)
+	<-started
+	h.Cancel()
+
+	select {
+	case <-h.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not stop after Cancel")
+	}
+	result, err := h.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if result == nil || result.StopReason != StopReasonCancelled {
+		t.Fatalf("expected partial result with StopReason=%s, got %+v", StopReasonCancelled, result)
+	}
+	if len(result.Messages) != 1 {
+		t.Errorf("expected the input message in the partial result, got %d messages", len(result.Messages))
+	}
+}
+
+func TestRunnerCancel_Unknown(t *testing.T) {
+	if NewRunner(&openai.Client{}).Cancel("run_missing") {
+		t.Error("expected Cancel to report unknown run")
+	}
+}
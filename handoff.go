@@ -0,0 +1,120 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// handoffToolPrefix starts the name of the synthetic tool for a handoff
+const handoffToolPrefix = "transfer_to_"
+
+// Handoff transfers the conversation to another agent. The runner offers
+// each of Agent.Handoffs to the model as a tool without parameters; calling
+// it makes Agent the current agent for the rest of the run.
+type Handoff struct {
+	// Agent is the agent that takes over the conversation. A run fails
+	// with ErrInvalidHandoff if it is nil.
+	Agent *Agent
+
+	// ToolName is the name of the tool offered to the model.
+	// Defaults to "transfer_to_" followed by the agent's name in snake case.
+	// A run fails with ErrDuplicateTool if another tool has the same name.
+	ToolName string
+
+	// Description tells the model when to hand off.
+	// Defaults to "Transfer the conversation to <agent name>."
+	Description string
+
+	// InputFilter, if set, rewrites the history the next agent sees, e.g.
+	// to drop earlier tool calls. It receives the full history including
+	// the handoff call and its tool message, and must keep every remaining
+	// tool call answered.
+	InputFilter func([]openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion
+}
+
+// NewHandoff creates a Handoff to agent with the default tool name and description.
+func NewHandoff(agent *Agent) *Handoff {
+	return &Handoff{Agent: agent}
+}
+
+// WithDescription sets the description and returns the handoff for chaining.
+func (h *Handoff) WithDescription(description string) *Handoff {
+	h.Description = description
+	return h
+}
+
+// WithInputFilter sets the input filter and returns the handoff for chaining.
+func (h *Handoff) WithInputFilter(filter func([]openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion) *Handoff {
+	h.InputFilter = filter
+	return h
+}
+
+// tool returns the synthetic tool the model calls to hand off.
+func (h *Handoff) tool() Tool {
+	name := h.ToolName
+	if name == "" {
+		name = handoffToolPrefix + snakeCase(h.Agent.Name)
+	}
+	description := h.Description
+	if description == "" {
+		description = fmt.Sprintf("Transfer the conversation to %s.", h.Agent.Name)
+	}
+	return Tool{
+		Name:        name,
+		Description: description,
+		Callback: func(map[string]any, ContextVariables) (any, error) {
+			return h, nil
+		},
+	}
+}
+
+// apply hands the conversation from agent over to h.Agent and returns the
+// history the next agent sees.
+func (h *Handoff) apply(ctx context.Context, from *Agent, history []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	if h.Agent != from {
+		eventsFromContext(ctx).emit(EventHandoff, from, map[string]any{"from": from.Name, "to": h.Agent.Name})
+	}
+	if h.InputFilter != nil {
+		return h.InputFilter(history)
+	}
+	return history
+}
+
+// snakeCase lowercases name and replaces every run of characters not
+// allowed in a tool name with an underscore.
+func snakeCase(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			sep = false
+			continue
+		}
+		sep = true
+	}
+	return b.String()
+}
+
+// asHandoff reports whether a tool result requests a handoff. Besides a
+// *Handoff, a bare *Agent is accepted for tools written before Handoff
+// existed.
+func asHandoff(result any) (*Handoff, bool) {
+	switch v := result.(type) {
+	case *Handoff:
+		if v != nil && v.Agent != nil {
+			return v, true
+		}
+	case *Agent:
+		if v != nil {
+			return &Handoff{Agent: v}, true
+		}
+	}
+	return nil, false
+}
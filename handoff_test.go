@@ -0,0 +1,201 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestHandoff_Tool(t *testing.T) {
+	billing := NewAgent("Billing Support")
+
+	tests := []struct {
+		name            string
+		handoff         *Handoff
+		wantName        string
+		wantDescription string
+	}{
+		{
+			name:            "defaults",
+			handoff:         NewHandoff(billing),
+			wantName:        "transfer_to_billing_support",
+			wantDescription: "Transfer the conversation to Billing Support.",
+		},
+		{
+			name:            "custom",
+			handoff:         &Handoff{Agent: billing, ToolName: "escalate", Description: "Escalate billing issues"},
+			wantName:        "escalate",
+			wantDescription: "Escalate billing issues",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := tt.handoff.tool()
+			if tool.Name != tt.wantName {
+				t.Errorf("expected name %q, got %q", tt.wantName, tool.Name)
+			}
+			if tool.Description != tt.wantDescription {
+				t.Errorf("expected description %q, got %q", tt.wantDescription, tool.Description)
+			}
+			result, err := tool.Execute(`{}`, nil)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if agent, ok := IsHandoff(result); !ok || agent != billing {
+				t.Errorf("expected the tool to hand off to billing, got %v", result)
+			}
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Billing":            "billing",
+		"Billing Support":    "billing_support",
+		"  Tech -- Support!": "tech_support",
+		"agent2":             "agent2",
+	}
+	for in, want := range tests {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAgent_ToolSchemasIncludeHandoffs(t *testing.T) {
+	agent := NewAgent("Triage")
+	agent.Tools = []Tool{{Name: "lookup", Description: "Look up"}}
+	agent.Handoffs = []*Handoff{NewHandoff(NewAgent("Billing"))}
+
+	schemas := agent.ToolSchemas()
+	if len(schemas) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(schemas))
+	}
+	if schemas[0].Function.Name != "lookup" || schemas[1].Function.Name != "transfer_to_billing" {
+		t.Errorf("expected tools before handoffs, got %s, %s", schemas[0].Function.Name, schemas[1].Function.Name)
+	}
+}
+
+func TestRun_Handoff(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{ID: "call_1", Name: "transfer_to_billing", Arguments: `{}`}),
+		textCompletion("Your invoice is on its way."),
+	)
+
+	billing := NewAgent("Billing")
+	billing.Instructions = "You handle billing."
+
+	var filtered []openai.ChatCompletionMessageParamUnion
+	triage := NewAgent("Triage")
+	triage.Handoffs = []*Handoff{
+		NewHandoff(billing).WithInputFilter(func(history []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+			filtered = history
+			// Keep only the user's messages
+			var kept []openai.ChatCompletionMessageParamUnion
+			for _, msg := range history {
+				if msg.OfUser != nil {
+					kept = append(kept, msg)
+				}
+			}
+			return kept
+		}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Where is my invoice?")}
+	result, err := runner.Run(context.Background(), triage, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if got := result.Steps[len(result.Steps)-1].AgentName; got != "Billing" {
+		t.Errorf("expected billing to finish the run, got %s", got)
+	}
+	if len(filtered) != 3 {
+		t.Errorf("expected the filter to see user, assistant and tool messages, got %d", len(filtered))
+	}
+	call := result.Steps[0].ToolCalls[0]
+	if call.Error != nil {
+		t.Errorf("unexpected tool error: %v", call.Error)
+	}
+
+	// The next agent sees its own instructions and the filtered history
+	requests := mock.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	second := requests[1]["messages"].([]any)
+	if len(second) != 2 {
+		t.Fatalf("expected system and user messages after the filter, got %d", len(second))
+	}
+	if role := second[1].(map[string]any)["role"]; role != "user" {
+		t.Errorf("expected user message, got %v", role)
+	}
+}
+
+func TestRun_InvalidHandoffs(t *testing.T) {
+	billing := NewAgent("Billing")
+
+	tests := []struct {
+		name     string
+		tools    []Tool
+		handoffs []*Handoff
+		wantErr  error
+	}{
+		{
+			name:     "nil agent",
+			handoffs: []*Handoff{NewHandoff(nil)},
+			wantErr:  ErrInvalidHandoff,
+		},
+		{
+			name:     "nil handoff",
+			handoffs: []*Handoff{nil},
+			wantErr:  ErrInvalidHandoff,
+		},
+		{
+			name:     "name collides with tool",
+			tools:    []Tool{{Name: "transfer_to_billing", Description: "Not a handoff"}},
+			handoffs: []*Handoff{NewHandoff(billing)},
+			wantErr:  ErrDuplicateTool,
+		},
+		{
+			name:     "two handoffs with one name",
+			handoffs: []*Handoff{NewHandoff(billing), {Agent: NewAgent("Support"), ToolName: "transfer_to_billing"}},
+			wantErr:  ErrDuplicateTool,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, mock := newMockRunner(t, textCompletion("unused"))
+			triage := NewAgent("Triage")
+			triage.Tools = tt.tools
+			triage.Handoffs = tt.handoffs
+
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Where is my invoice?")}
+			_, err := runner.Run(context.Background(), triage, messages, nil, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if n := len(mock.Requests()); n != 0 {
+				t.Errorf("expected no LLM calls, got %d", n)
+			}
+		})
+	}
+}
+
+func TestIsHandoff_Handoff(t *testing.T) {
+	billing := NewAgent("Billing")
+
+	if agent, ok := IsHandoff(NewHandoff(billing)); !ok || agent != billing {
+		t.Errorf("expected a Handoff to be recognized, got %v, %v", agent, ok)
+	}
+	if _, ok := IsHandoff(&Handoff{}); ok {
+		t.Error("expected a Handoff without an agent to be ignored")
+	}
+	if _, ok := IsHandoff((*Agent)(nil)); ok {
+		t.Error("expected a nil agent to be ignored")
+	}
+}
@@ -0,0 +1,222 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation describes where and how a value breaks a schema.
+type Violation struct {
+	// Path locates the offending value, e.g. "$.items[2].name"
+	Path string `json:"path"`
+
+	// Message explains the violation
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// CheckJSON decodes data and checks it against the schema. Invalid JSON is
+// reported as an error; a value that breaks the schema as violations.
+func (s *Schema) CheckJSON(data []byte) ([]Violation, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("not valid JSON: %w", err)
+	}
+	return s.Check(value), nil
+}
+
+// Check checks a decoded JSON value, as produced by json.Unmarshal into an
+// any, against the schema. It covers type, enum, required and additional
+// properties, string length, pattern, numeric bounds and the oneOf, anyOf
+// and allOf keywords, and returns every violation found. Properties are
+// visited in name order, so the result is deterministic.
+func (s *Schema) Check(value any) []Violation {
+	var violations []Violation
+	s.check("$", value, 0, &violations)
+	return violations
+}
+
+func (s *Schema) check(path string, value any, depth int, violations *[]Violation) {
+	if s == nil {
+		return
+	}
+	report := reporter(path, violations)
+	if depth > maxDepth {
+		report("nesting exceeds %d levels", maxDepth)
+		return
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		report("expected %s, got %s", s.Type, typeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		report("value %s is not one of the allowed values", quote(value))
+	}
+	s.checkCombinators(path, value, depth, violations)
+
+	switch v := value.(type) {
+	case map[string]any:
+		s.checkObject(path, v, depth, violations)
+	case []any:
+		for i, item := range v {
+			s.Items.check(fmt.Sprintf("%s[%d]", path, i), item, depth+1, violations)
+		}
+	case string:
+		s.checkString(v, report)
+	case float64:
+		s.checkNumber(v, report)
+	}
+}
+
+// reporter returns a function that records a violation at path.
+func reporter(path string, violations *[]Violation) func(format string, args ...any) {
+	return func(format string, args ...any) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// checkCombinators checks value against the allOf, anyOf and oneOf schemas.
+func (s *Schema) checkCombinators(path string, value any, depth int, violations *[]Violation) {
+	report := reporter(path, violations)
+	for _, sub := range s.AllOf {
+		sub.check(path, value, depth+1, violations)
+	}
+	if len(s.AnyOf) > 0 && countMatching(s.AnyOf, value, depth) == 0 {
+		report("value matches none of the anyOf schemas")
+	}
+	if n := countMatching(s.OneOf, value, depth); len(s.OneOf) > 0 && n != 1 {
+		report("value matches %d of the oneOf schemas, expected exactly one", n)
+	}
+}
+
+// checkObject checks the required, known and additional properties of an
+// object.
+func (s *Schema) checkObject(path string, v map[string]any, depth int, violations *[]Violation) {
+	report := reporter(path, violations)
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			report("missing required property %q", name)
+		}
+	}
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		prop, known := s.Properties[name]
+		if !known {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				report("unexpected property %q", name)
+			}
+			continue
+		}
+		prop.check(path+"."+name, v[name], depth+1, violations)
+	}
+}
+
+// checkString checks the length and pattern of a string.
+func (s *Schema) checkString(v string, report func(format string, args ...any)) {
+	n := utf8.RuneCountInString(v)
+	if s.MinLength != nil && n < *s.MinLength {
+		report("length %d is less than minimum %d", n, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		report("length %d is greater than maximum %d", n, *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		switch {
+		case err != nil:
+			report("invalid pattern %q: %v", s.Pattern, err)
+		case !re.MatchString(v):
+			report("value %q does not match pattern %q", v, s.Pattern)
+		}
+	}
+}
+
+// checkNumber checks the bounds of a number.
+func (s *Schema) checkNumber(v float64, report func(format string, args ...any)) {
+	if s.Minimum != nil && v < *s.Minimum {
+		report("value %v is less than minimum %v", v, *s.Minimum)
+	}
+	if s.Maximum != nil && v > *s.Maximum {
+		report("value %v is greater than maximum %v", v, *s.Maximum)
+	}
+}
+
+// countMatching counts the schemas value has no violations against.
+func countMatching(schemas []*Schema, value any, depth int) int {
+	n := 0
+	for _, sub := range schemas {
+		var violations []Violation
+		sub.check("$", value, depth+1, &violations)
+		if len(violations) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Schema) inEnum(value any) bool {
+	for _, e := range s.Enum {
+		if jv, err := jsonValue(e); err == nil && reflect.DeepEqual(jv, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasType reports whether a decoded JSON value is of type t.
+func hasType(value any, t Type) bool {
+	switch t {
+	case TypeInteger:
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case TypeNumber:
+		_, ok := value.(float64)
+		return ok
+	default:
+		return typeOf(value) == t
+	}
+}
+
+// typeOf returns the JSON type of a decoded value.
+func typeOf(value any) Type {
+	switch value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case float64:
+		return TypeNumber
+	case string:
+		return TypeString
+	case []any:
+		return TypeArray
+	case map[string]any:
+		return TypeObject
+	default:
+		return Type(fmt.Sprintf("%T", value))
+	}
+}
+
+// quote renders a value for a violation message.
+func quote(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(data))
+}
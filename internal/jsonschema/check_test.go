@@ -0,0 +1,78 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckJSON(t *testing.T) {
+	schema := Object().
+		WithProperty("name", String().WithMinLength(2).WithPattern(`^[A-Z]`)).
+		WithProperty("age", Integer().WithMinimum(0).WithMaximum(150)).
+		WithProperty("role", String().WithEnum("admin", "user")).
+		WithProperty("tags", Array(String().WithMaxLength(3))).
+		WithRequired("name", "age")
+
+	tests := []struct {
+		name  string
+		input string
+		want  []Violation
+	}{
+		{
+			name:  "valid",
+			input: `{"name": "Ada", "age": 36, "role": "admin", "tags": ["a", "bc"]}`,
+		},
+		{
+			name:  "missing required",
+			input: `{"name": "Ada"}`,
+			want:  []Violation{{Path: "$", Message: `missing required property "age"`}},
+		},
+		{
+			name:  "wrong types",
+			input: `{"name": 7, "age": 36.5}`,
+			want: []Violation{
+				{Path: "$.age", Message: "expected integer, got number"},
+				{Path: "$.name", Message: "expected string, got number"},
+			},
+		},
+		{
+			name:  "constraints",
+			input: `{"name": "a", "age": 200, "role": "root", "tags": ["long"]}`,
+			want: []Violation{
+				{Path: "$.age", Message: "value 200 is greater than maximum 150"},
+				{Path: "$.name", Message: "length 1 is less than minimum 2"},
+				{Path: "$.name", Message: `value "a" does not match pattern "^[A-Z]"`},
+				{Path: "$.role", Message: `value "root" is not one of the allowed values`},
+				{Path: "$.tags[0]", Message: "length 4 is greater than maximum 3"},
+			},
+		},
+		{
+			name:  "additional property",
+			input: `{"name": "Ada", "age": 36, "email": "ada@example.com"}`,
+			want:  []Violation{{Path: "$", Message: `unexpected property "email"`}},
+		},
+		{
+			name:  "wrong root type",
+			input: `[1, 2]`,
+			want:  []Violation{{Path: "$", Message: "expected object, got array"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.CheckJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckJSON_InvalidJSON(t *testing.T) {
+	if _, err := String().CheckJSON([]byte(`{"unterminated`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
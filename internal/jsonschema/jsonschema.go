@@ -41,6 +41,9 @@ type Schema struct {
 	Minimum              *float64           `json:"minimum,omitempty"`
 	Maximum              *float64           `json:"maximum,omitempty"`
 	Pattern              string             `json:"pattern,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
 }
 
 // NewSchema creates a new JSON schema with the given type.
@@ -78,6 +81,28 @@ func Object() *Schema {
 	}
 }
 
+// Null creates a null type schema, e.g. for nullable fields with AnyOf.
+func Null() *Schema {
+	return &Schema{Type: TypeNull}
+}
+
+// OneOf creates a schema matching exactly one of the given schemas.
+func OneOf(schemas ...*Schema) *Schema {
+	return &Schema{OneOf: schemas}
+}
+
+// AnyOf creates a schema matching at least one of the given schemas.
+// Structured outputs support anyOf, e.g. AnyOf(String(), Null()) for a
+// nullable string, but not oneOf or allOf.
+func AnyOf(schemas ...*Schema) *Schema {
+	return &Schema{AnyOf: schemas}
+}
+
+// AllOf creates a schema matching all of the given schemas.
+func AllOf(schemas ...*Schema) *Schema {
+	return &Schema{AllOf: schemas}
+}
+
 // Array creates an array type schema.
 func Array(items *Schema) *Schema {
 	return &Schema{
@@ -158,24 +183,134 @@ func (s *Schema) ToJSON() (string, error) {
 	return string(data), nil
 }
 
+// maxDepth bounds schema nesting in ToMap, guarding against cyclic schemas
+const maxDepth = 1000
+
 // ToMap converts the schema to a map[string]any for use with OpenAI API.
+// The map is built directly from the schema, without a JSON round trip,
+// and holds the same values decoding the schema's JSON would produce.
 func (s *Schema) ToMap() (map[string]any, error) {
-	data, err := json.Marshal(s)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	return s.toMap(0)
+}
+
+func (s *Schema) toMap(depth int) (map[string]any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("failed to convert schema: nesting exceeds %d levels", maxDepth)
 	}
 
-	var result map[string]any
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal schema: %w", err)
+	m := make(map[string]any, 4)
+	if s.Type != "" {
+		m["type"] = string(s.Type)
+	}
+	if s.Description != "" {
+		m["description"] = s.Description
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			if prop == nil {
+				props[name] = nil
+				continue
+			}
+			pm, err := prop.toMap(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			props[name] = pm
+		}
+		m["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		required := make([]any, len(s.Required))
+		for i, r := range s.Required {
+			required[i] = r
+		}
+		m["required"] = required
+	}
+	if s.Items != nil {
+		items, err := s.Items.toMap(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m["items"] = items
+	}
+	if len(s.Enum) > 0 {
+		enum := make([]any, len(s.Enum))
+		for i, v := range s.Enum {
+			jv, err := jsonValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal schema: %w", err)
+			}
+			enum[i] = jv
+		}
+		m["enum"] = enum
+	}
+	if s.AdditionalProperties != nil {
+		m["additionalProperties"] = *s.AdditionalProperties
+	}
+	if s.MinLength != nil {
+		m["minLength"] = float64(*s.MinLength)
+	}
+	if s.MaxLength != nil {
+		m["maxLength"] = float64(*s.MaxLength)
+	}
+	if s.Minimum != nil {
+		m["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		m["maximum"] = *s.Maximum
+	}
+	if s.Pattern != "" {
+		m["pattern"] = s.Pattern
+	}
+	for key, schemas := range map[string][]*Schema{"oneOf": s.OneOf, "anyOf": s.AnyOf, "allOf": s.AllOf} {
+		if len(schemas) == 0 {
+			continue
+		}
+		list := make([]any, len(schemas))
+		for i, sub := range schemas {
+			if sub == nil {
+				continue
+			}
+			sm, err := sub.toMap(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = sm
+		}
+		m[key] = list
+	}
+	return m, nil
+}
+
+// jsonValue returns v as encoding/json would decode it into an any. Common
+// scalar types are converted directly; others take a JSON round trip.
+func jsonValue(v any) (any, error) {
+	switch v := v.(type) {
+	case nil, string, bool, float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
 	}
 
-	return result, nil
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// Validate performs basic validation on the schema.
+// Validate performs basic validation on the schema. A schema without a
+// type is valid if it combines other schemas with oneOf, anyOf or allOf.
 func (s *Schema) Validate() error {
-	if s.Type == "" {
+	composed := len(s.OneOf) > 0 || len(s.AnyOf) > 0 || len(s.AllOf) > 0
+	if s.Type == "" && !composed {
 		return fmt.Errorf("schema type is required")
 	}
 
@@ -202,5 +337,19 @@ func (s *Schema) Validate() error {
 		}
 	}
 
+	for _, group := range []struct {
+		keyword string
+		schemas []*Schema
+	}{{"oneOf", s.OneOf}, {"anyOf", s.AnyOf}, {"allOf", s.AllOf}} {
+		for i, sub := range group.schemas {
+			if sub == nil {
+				return fmt.Errorf("invalid %s schema %d: schema is nil", group.keyword, i)
+			}
+			if err := sub.Validate(); err != nil {
+				return fmt.Errorf("invalid %s schema %d: %w", group.keyword, i, err)
+			}
+		}
+	}
+
 	return nil
 }
@@ -2,6 +2,7 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -257,3 +258,173 @@ func TestComplexNestedSchema(t *testing.T) {
 		t.Error("items type should be object")
 	}
 }
+
+func TestJSONSchemaArray(t *testing.T) {
+	person := Object().WithProperty("name", String()).WithRequired("name")
+	rf := JSONSchemaArray("people", person)
+
+	if err := rf.Validate(); err != nil {
+		t.Fatalf("expected valid response format, got %v", err)
+	}
+	if !rf.JSONSchema.ArrayWrapped {
+		t.Error("expected ArrayWrapped to be set")
+	}
+
+	root := rf.JSONSchema.Schema
+	if root.Type != TypeObject {
+		t.Errorf("expected object root, got %s", root.Type)
+	}
+	items := root.Properties[ArrayWrapperKey]
+	if items == nil || items.Type != TypeArray || items.Items != person {
+		t.Error("expected wrapped array of the item schema")
+	}
+	if len(root.Required) != 1 || root.Required[0] != ArrayWrapperKey {
+		t.Errorf("expected %q to be required, got %v", ArrayWrapperKey, root.Required)
+	}
+}
+
+// roundTripMap converts a schema to a map through JSON, as ToMap used to.
+func roundTripMap(t testing.TB, s *Schema) map[string]any {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	return m
+}
+
+// benchmarkSchema is a representative structured output schema.
+func benchmarkSchema() *Schema {
+	type level string
+	return Object().
+		WithDescription("Support ticket").
+		WithProperty("title", String().WithMinLength(1).WithMaxLength(120)).
+		WithProperty("priority", String().WithEnum("low", "medium", level("high"))).
+		WithProperty("score", Number().WithMinimum(0).WithMaximum(1)).
+		WithProperty("retries", Integer().WithEnum(0, 1, int64(2))).
+		WithProperty("contact", Object().
+			WithProperty("email", String().WithPattern("^.+@.+$")).
+			WithRequired("email")).
+		WithProperty("tags", Array(String())).
+		WithRequired("title", "priority")
+}
+
+func TestToMap_MatchesJSON(t *testing.T) {
+	s := benchmarkSchema()
+
+	got, err := s.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap failed: %v", err)
+	}
+	if want := roundTripMap(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap differs from JSON round trip:\ngot  %#v\nwant %#v", got, want)
+	}
+}
+
+func TestToMap_Cycle(t *testing.T) {
+	s := Object()
+	s.WithProperty("self", s)
+	if _, err := s.ToMap(); err == nil {
+		t.Error("expected error for cyclic schema")
+	}
+}
+
+func TestComposition_NullableField(t *testing.T) {
+	s := Object().
+		WithProperty("nickname", AnyOf(String(), Null())).
+		WithRequired("nickname")
+
+	if err := s.Validate(); err != nil {
+		t.Fatalf("expected anyOf property to validate, got %v", err)
+	}
+
+	got, err := s.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap failed: %v", err)
+	}
+	if want := roundTripMap(t, s); !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap differs from JSON round trip:\ngot  %#v\nwant %#v", got, want)
+	}
+	nickname := got["properties"].(map[string]any)["nickname"].(map[string]any)
+	want := map[string]any{"anyOf": []any{
+		map[string]any{"type": "string"},
+		map[string]any{"type": "null"},
+	}}
+	if !reflect.DeepEqual(nickname, want) {
+		t.Errorf("expected %v, got %v", want, nickname)
+	}
+
+	for input, wantViolations := range map[string]int{
+		`{"nickname": "Ada"}`: 0,
+		`{"nickname": null}`:  0,
+		`{"nickname": 7}`:     1,
+	} {
+		violations, err := s.CheckJSON([]byte(input))
+		if err != nil {
+			t.Fatalf("CheckJSON failed: %v", err)
+		}
+		if len(violations) != wantViolations {
+			t.Errorf("%s: expected %d violations, got %v", input, wantViolations, violations)
+		}
+	}
+}
+
+func TestComposition_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  *Schema
+		wantErr bool
+	}{
+		{name: "oneOf", schema: OneOf(String(), Integer())},
+		{name: "allOf", schema: AllOf(Object(), Object().WithProperty("id", String()))},
+		{name: "invalid member", schema: AnyOf(String(), &Schema{}), wantErr: true},
+		{name: "nil member", schema: OneOf(nil), wantErr: true},
+		{name: "empty", schema: &Schema{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.schema.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestComposition_Check(t *testing.T) {
+	oneOf := OneOf(Number().WithMinimum(0), Integer())
+	if v := oneOf.Check(1.5); len(v) != 0 {
+		t.Errorf("expected 1.5 to match only the number schema, got %v", v)
+	}
+	if v := oneOf.Check(float64(2)); len(v) != 1 {
+		t.Errorf("expected 2 to match both schemas and fail oneOf, got %v", v)
+	}
+
+	allOf := AllOf(String().WithMinLength(2), String().WithMaxLength(3))
+	if v := allOf.Check("abcd"); len(v) != 1 || v[0].Message != "length 4 is greater than maximum 3" {
+		t.Errorf("expected the maxLength violation, got %v", v)
+	}
+}
+
+func BenchmarkToMap(b *testing.B) {
+	s := benchmarkSchema()
+
+	b.Run("direct", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := s.ToMap(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json_round_trip", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			roundTripMap(b, s)
+		}
+	})
+}
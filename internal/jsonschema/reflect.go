@@ -0,0 +1,234 @@
+package jsonschema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// For generates the schema of T. See Reflect.
+func For[T any]() (*Schema, error) {
+	return Reflect(reflect.TypeFor[T]())
+}
+
+// Reflect generates a schema from a Go type, following encoding/json
+// conventions: properties are named after the json tag, fields tagged "-"
+// and unexported fields are skipped, and embedded structs are flattened.
+// Fields are required unless they are pointers or tagged omitempty.
+// time.Time is a string, []byte a (base64) string and maps with string keys
+// are objects with arbitrary properties. Interfaces, channels, functions
+// and recursive types are not supported.
+//
+// The jsonschema struct tag refines a property with comma-separated
+// options; write "\," for a comma inside a value:
+//
+//	description=...   sets the description
+//	enum=a|b|c        restricts the value; values are parsed as the field's type
+//	minimum=, maximum=, minLength=, maxLength=, pattern=
+//	required          marks a pointer or omitempty field as required
+//	optional          marks a field as not required
+func Reflect(t reflect.Type) (*Schema, error) {
+	return reflectType(t, make(map[reflect.Type]bool))
+}
+
+func reflectType(t reflect.Type, visiting map[reflect.Type]bool) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return String(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return String(), nil
+	case reflect.Bool:
+		return Boolean(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer(), nil
+	case reflect.Float32, reflect.Float64:
+		return Number(), nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return String(), nil
+		}
+		items, err := reflectType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return Array(items), nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		return Object().WithAdditionalProperties(true), nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("recursive type %s is not supported", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		obj := Object()
+		if err := addFields(obj, t, visiting); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// addFields adds the properties of struct type t to obj.
+func addFields(obj *Schema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		jsonTag := f.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, jsonOpts, _ := strings.Cut(jsonTag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := addFields(obj, ft, visiting); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := reflectType(f.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		required := f.Type.Kind() != reflect.Pointer && !hasTagOption(jsonOpts, "omitempty")
+		if err := applyTag(prop, f.Tag.Get("jsonschema"), &required); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+
+		obj.WithProperty(name, prop)
+		if required {
+			obj.WithRequired(name)
+		}
+	}
+	return nil
+}
+
+// applyTag applies the options of a jsonschema struct tag to s.
+func applyTag(s *Schema, tag string, required *bool) error {
+	for _, opt := range splitTag(tag) {
+		key, value, _ := strings.Cut(opt, "=")
+		var err error
+		switch key {
+		case "":
+		case "description":
+			s.Description = value
+		case "enum":
+			for _, v := range strings.Split(value, "|") {
+				ev, perr := enumValue(s.Type, v)
+				if perr != nil {
+					return perr
+				}
+				s.Enum = append(s.Enum, ev)
+			}
+		case "minimum":
+			var f float64
+			f, err = strconv.ParseFloat(value, 64)
+			s.Minimum = &f
+		case "maximum":
+			var f float64
+			f, err = strconv.ParseFloat(value, 64)
+			s.Maximum = &f
+		case "minLength":
+			var n int
+			n, err = strconv.Atoi(value)
+			s.MinLength = &n
+		case "maxLength":
+			var n int
+			n, err = strconv.Atoi(value)
+			s.MaxLength = &n
+		case "pattern":
+			s.Pattern = value
+		case "required":
+			*required = true
+		case "optional":
+			*required = false
+		default:
+			return fmt.Errorf("unknown jsonschema tag option %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid jsonschema tag option %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// enumValue parses an enum value as the schema's type.
+func enumValue(t Type, v string) (any, error) {
+	switch t {
+	case TypeInteger:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer enum value %q", v)
+		}
+		return n, nil
+	case TypeNumber:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number enum value %q", v)
+		}
+		return f, nil
+	case TypeBoolean:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean enum value %q", v)
+		}
+		return b, nil
+	default:
+		return v, nil
+	}
+}
+
+// splitTag splits a tag on commas not escaped with a backslash.
+func splitTag(tag string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			b.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(tag[i])
+		}
+	}
+	return append(parts, b.String())
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
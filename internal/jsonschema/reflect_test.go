@@ -0,0 +1,100 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reflectAddress struct {
+	City string `json:"city"`
+}
+
+type reflectBase struct {
+	ID int64 `json:"id"`
+}
+
+type reflectArgs struct {
+	reflectBase
+	Query    string            `json:"query" jsonschema:"description=Search query\\, in plain text,minLength=1"`
+	Unit     string            `json:"unit,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+	Limit    *int              `json:"limit" jsonschema:"minimum=1,maximum=50,enum=10|20"`
+	Tags     []string          `json:"tags" jsonschema:"optional"`
+	Address  reflectAddress    `json:"address"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Since    time.Time         `json:"since,omitempty" jsonschema:"required"`
+	Raw      []byte            `json:"raw,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+	NoTag    bool
+}
+
+func TestReflect(t *testing.T) {
+	s, err := For[reflectArgs]()
+	if err != nil {
+		t.Fatalf("For failed: %v", err)
+	}
+
+	wantRequired := []string{"id", "query", "address", "since", "NoTag"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("expected required %v, got %v", wantRequired, s.Required)
+	}
+
+	props := s.Properties
+	if len(props) != 10 {
+		t.Errorf("expected 10 properties, got %d", len(props))
+	}
+	if props["id"].Type != TypeInteger || props["NoTag"].Type != TypeBoolean || props["raw"].Type != TypeString {
+		t.Error("unexpected scalar property types")
+	}
+	if q := props["query"]; q.Description != "Search query, in plain text" || q.MinLength == nil || *q.MinLength != 1 {
+		t.Errorf("unexpected query schema %+v", q)
+	}
+	if !reflect.DeepEqual(props["unit"].Enum, []any{"celsius", "fahrenheit"}) {
+		t.Errorf("unexpected unit enum %v", props["unit"].Enum)
+	}
+	if l := props["limit"]; *l.Minimum != 1 || *l.Maximum != 50 || !reflect.DeepEqual(l.Enum, []any{int64(10), int64(20)}) {
+		t.Errorf("unexpected limit schema %+v", l)
+	}
+	if tags := props["tags"]; tags.Type != TypeArray || tags.Items.Type != TypeString {
+		t.Errorf("unexpected tags schema %+v", tags)
+	}
+	if addr := props["address"]; addr.Type != TypeObject || addr.Properties["city"] == nil || addr.Required[0] != "city" {
+		t.Errorf("unexpected address schema %+v", addr)
+	}
+	if labels := props["labels"]; labels.Type != TypeObject || !*labels.AdditionalProperties {
+		t.Errorf("unexpected labels schema %+v", labels)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("generated schema is invalid: %v", err)
+	}
+}
+
+type reflectNode struct {
+	Children []reflectNode `json:"children"`
+}
+
+func TestReflect_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{name: "recursive", typ: reflect.TypeFor[reflectNode]()},
+		{name: "interface", typ: reflect.TypeFor[struct{ V any }]()},
+		{name: "int map keys", typ: reflect.TypeFor[map[int]string]()},
+		{name: "unknown tag", typ: reflect.TypeFor[struct {
+			V string `jsonschema:"format=email"`
+		}]()},
+		{name: "bad enum", typ: reflect.TypeFor[struct {
+			V int `jsonschema:"enum=a|b"`
+		}]()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Reflect(tt.typ); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
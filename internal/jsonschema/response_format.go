@@ -28,8 +28,15 @@ type JSONSchemaFormat struct {
 
 	// Strict enables strict schema adherence (recommended for OpenAI)
 	Strict bool
+
+	// ArrayWrapped marks a schema created by JSONSchemaArray, whose array
+	// result is wrapped in an object under ArrayWrapperKey
+	ArrayWrapped bool
 }
 
+// ArrayWrapperKey is the property holding the array in schemas created by JSONSchemaArray.
+const ArrayWrapperKey = "items"
+
 // Text creates a text response format (default behavior).
 func Text() *ResponseFormat {
 	return &ResponseFormat{
@@ -49,6 +56,19 @@ func JSONSchema(name string, schema *Schema) *ResponseFormat {
 	}
 }
 
+// JSONSchemaArray creates a structured response format whose result is an
+// array of items. Structured outputs require an object at the root, so the
+// array is wrapped as {"items": [...]}; Result.Into unwraps it transparently.
+func JSONSchemaArray(name string, items *Schema) *ResponseFormat {
+	wrapper := Object().
+		WithProperty(ArrayWrapperKey, Array(items)).
+		WithRequired(ArrayWrapperKey)
+
+	format := JSONSchema(name, wrapper)
+	format.JSONSchema.ArrayWrapped = true
+	return format
+}
+
 // WithDescription sets the description for the JSON schema.
 func (r *ResponseFormat) WithDescription(desc string) *ResponseFormat {
 	if r.JSONSchema != nil {
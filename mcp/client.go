@@ -0,0 +1,253 @@
+// Package mcp connects agents to tools served over the Model Context
+// Protocol (MCP). A Client talks to an MCP server over stdio or HTTP,
+// lists its tools and adapts them to agents.Tool.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// ProtocolVersion is the MCP revision requested during initialization
+const ProtocolVersion = "2025-03-26"
+
+// clientName identifies this SDK to MCP servers
+const clientName = "openai-agents-go"
+
+// ErrClosed is returned for calls on a client whose connection is closed
+var ErrClosed = errors.New("mcp connection closed")
+
+// RPCError is a JSON-RPC error returned by the server.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// ToolInfo describes a tool offered by an MCP server.
+type ToolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// Content is one item of a tool call result.
+type Content struct {
+	// Type is "text", "image", "audio" or "resource"
+	Type string `json:"type"`
+
+	// Text is set for text content
+	Text string `json:"text,omitempty"`
+
+	// MimeType is set for image and audio content
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// CallToolResult is the result of a tool call.
+type CallToolResult struct {
+	Content []Content `json:"content"`
+
+	// IsError reports that the tool itself failed; Content describes why
+	IsError bool `json:"isError,omitempty"`
+}
+
+// Text joins the text content of the result, noting non-text items by type.
+func (r *CallToolResult) Text() string {
+	parts := make([]string, 0, len(r.Content))
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			parts = append(parts, c.Text)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("[%s content omitted]", c.Type))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// transport exchanges JSON-RPC messages with a server.
+type transport interface {
+	// call sends a request and waits for the matching response
+	call(ctx context.Context, req *rpcRequest) (*rpcResponse, error)
+
+	// notify sends a notification, which has no response
+	notify(ctx context.Context, req *rpcRequest) error
+
+	close() error
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// Client is a connection to an MCP server. It is safe for concurrent use.
+type Client struct {
+	transport transport
+	nextID    atomic.Int64
+
+	// ServerName and ServerVersion are reported by the server on connect
+	ServerName    string
+	ServerVersion string
+}
+
+// newClient performs the initialization handshake over t.
+func newClient(ctx context.Context, t transport) (*Client, error) {
+	c := &Client{transport: t}
+
+	var init struct {
+		ServerInfo struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	err := c.request(ctx, "initialize", map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": clientName, "version": "1.0.0"},
+	}, &init)
+	if err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("mcp initialize failed: %w", err)
+	}
+	c.ServerName = init.ServerInfo.Name
+	c.ServerVersion = init.ServerInfo.Version
+
+	if err := t.notify(ctx, &rpcRequest{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("mcp initialize failed: %w", err)
+	}
+	return c, nil
+}
+
+// request calls method and decodes the result into out.
+func (c *Client) request(ctx context.Context, method string, params any, out any) error {
+	id := c.nextID.Add(1)
+	resp, err := c.transport.call(ctx, &rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, out); err != nil {
+		return fmt.Errorf("invalid %s result: %w", method, err)
+	}
+	return nil
+}
+
+// ListTools returns all tools offered by the server, following pagination.
+func (c *Client) ListTools(ctx context.Context) ([]ToolInfo, error) {
+	var tools []ToolInfo
+	var cursor string
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var page struct {
+			Tools      []ToolInfo `json:"tools"`
+			NextCursor string     `json:"nextCursor"`
+		}
+		if err := c.request(ctx, "tools/list", params, &page); err != nil {
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool calls the named tool with the given arguments.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (*CallToolResult, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	var result CallToolResult
+	if err := c.request(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		return nil, fmt.Errorf("failed to call tool %s: %w", name, err)
+	}
+	return &result, nil
+}
+
+// Tools lists the server's tools and adapts them to agents.Tool. Calling
+// an adapted tool calls the tool on the server and returns its text
+// content; results the server flags as errors are returned as errors, so
+// the model sees them as failed tool calls.
+func (c *Client) Tools(ctx context.Context) ([]agents.Tool, error) {
+	infos, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make([]agents.Tool, 0, len(infos))
+	for _, info := range infos {
+		tools = append(tools, c.adapt(info))
+	}
+	return tools, nil
+}
+
+// adapt converts a server tool into an agents.Tool.
+func (c *Client) adapt(info ToolInfo) agents.Tool {
+	name := info.Name
+	return agents.Tool{
+		Name:        name,
+		Description: info.Description,
+		Parameters:  inputSchema(info.InputSchema),
+		CallbackWithContext: func(ctx context.Context, args map[string]any, _ agents.ContextVariables) (any, error) {
+			result, err := c.CallTool(ctx, name, args)
+			if err != nil {
+				return nil, err
+			}
+			if result.IsError {
+				return nil, errors.New(result.Text())
+			}
+			return result.Text(), nil
+		},
+	}
+}
+
+// inputSchema makes an MCP input schema acceptable as function parameters,
+// which must be an object schema with properties.
+func inputSchema(schema map[string]any) map[string]any {
+	params := make(map[string]any, len(schema)+2)
+	for k, v := range schema {
+		params[k] = v
+	}
+	if _, ok := params["type"]; !ok {
+		params["type"] = "object"
+	}
+	if _, ok := params["properties"]; !ok {
+		params["properties"] = map[string]any{}
+	}
+	return params
+}
+
+// Close closes the connection and, for stdio servers, stops the process.
+func (c *Client) Close() error {
+	return c.transport.close()
+}
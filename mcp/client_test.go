@@ -0,0 +1,285 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer answers MCP requests with two tools, "echo" and "fail", and
+// serves tools/list in two pages.
+func fakeServer(method string, params json.RawMessage) (any, *RPCError) {
+	switch method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "fake", "version": "0.1"},
+		}, nil
+	case "tools/list":
+		var p struct {
+			Cursor string `json:"cursor"`
+		}
+		_ = json.Unmarshal(params, &p)
+		if p.Cursor == "" {
+			return map[string]any{
+				"tools": []map[string]any{{
+					"name":        "echo",
+					"description": "Echo the text",
+					"inputSchema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"text": map[string]any{"type": "string"}},
+					},
+				}},
+				"nextCursor": "page2",
+			}, nil
+		}
+		return map[string]any{
+			"tools": []map[string]any{{"name": "fail", "inputSchema": map[string]any{}}},
+		}, nil
+	case "tools/call":
+		var p struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		_ = json.Unmarshal(params, &p)
+		if p.Name == "fail" {
+			return map[string]any{
+				"content": []map[string]any{{"type": "text", "text": "boom"}},
+				"isError": true,
+			}, nil
+		}
+		return map[string]any{
+			"content": []map[string]any{
+				{"type": "text", "text": fmt.Sprintf("echo: %v", p.Arguments["text"])},
+				{"type": "image", "data": "AAAA", "mimeType": "image/png"},
+			},
+		}, nil
+	default:
+		return nil, &RPCError{Code: -32601, Message: "method not found"}
+	}
+}
+
+type serverMessage struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+func serverReply(msg serverMessage) map[string]any {
+	result, rpcErr := fakeServer(msg.Method, msg.Params)
+	reply := map[string]any{"jsonrpc": "2.0", "id": *msg.ID}
+	if rpcErr != nil {
+		reply["error"] = rpcErr
+	} else {
+		reply["result"] = result
+	}
+	return reply
+}
+
+// newPipeClient connects a client to fakeServer over in-memory streams.
+// The server sends a notification before answering each call.
+func newPipeClient(t *testing.T) (*Client, func() []string) {
+	t.Helper()
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	var mu sync.Mutex
+	var received []string
+	go func() {
+		enc := json.NewEncoder(serverW)
+		scanner := bufio.NewScanner(serverR)
+		for scanner.Scan() {
+			var msg serverMessage
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				continue
+			}
+			mu.Lock()
+			received = append(received, msg.Method)
+			mu.Unlock()
+			if msg.ID == nil || msg.Method == "" {
+				continue
+			}
+			_ = enc.Encode(map[string]any{"jsonrpc": "2.0", "method": "notifications/message"})
+			_ = enc.Encode(serverReply(msg))
+		}
+		_ = serverW.Close()
+	}()
+
+	client, err := newClient(context.Background(), newStreamTransport(clientR, clientW))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), received...)
+	}
+}
+
+func newHTTPClient(t *testing.T) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+		var msg serverMessage
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		if msg.Method == "initialize" {
+			w.Header().Set(sessionHeader, "session-1")
+		} else if r.Header.Get(sessionHeader) != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+		if msg.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		data, _ := json.Marshal(serverReply(msg))
+		if msg.Method != "tools/call" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(data)
+			return
+		}
+		// Stream tool results, preceded by an unrelated notification
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+		fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := ConnectHTTP(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestClient_Tools(t *testing.T) {
+	transports := map[string]func(*testing.T) *Client{
+		"stdio": func(t *testing.T) *Client {
+			c, _ := newPipeClient(t)
+			return c
+		},
+		"http": newHTTPClient,
+	}
+
+	for name, connect := range transports {
+		t.Run(name, func(t *testing.T) {
+			client := connect(t)
+			if client.ServerName != "fake" {
+				t.Errorf("expected server name, got %q", client.ServerName)
+			}
+
+			tools, err := client.Tools(context.Background())
+			if err != nil {
+				t.Fatalf("Tools failed: %v", err)
+			}
+			if len(tools) != 2 || tools[0].Name != "echo" || tools[1].Name != "fail" {
+				t.Fatalf("expected both pages of tools, got %v", tools)
+			}
+			if tools[1].Parameters["type"] != "object" || tools[1].Parameters["properties"] == nil {
+				t.Errorf("expected empty schema to become an object schema, got %v", tools[1].Parameters)
+			}
+
+			result, err := tools[0].Execute(`{"text": "hi"}`, nil)
+			if err != nil {
+				t.Fatalf("echo failed: %v", err)
+			}
+			if result != "echo: hi\n[image content omitted]" {
+				t.Errorf("unexpected echo result %q", result)
+			}
+
+			if _, err := tools[1].Execute(`{}`, nil); err == nil || err.Error() != "boom" {
+				t.Errorf("expected tool error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_Handshake(t *testing.T) {
+	client, received := newPipeClient(t)
+	if _, err := client.ListTools(context.Background()); err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	got := strings.Join(received(), ",")
+	if !strings.HasPrefix(got, "initialize,notifications/initialized,tools/list") {
+		t.Errorf("expected initialize handshake, got %s", got)
+	}
+}
+
+func TestClient_RPCError(t *testing.T) {
+	client, _ := newPipeClient(t)
+	err := client.request(context.Background(), "resources/list", nil, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("expected method not found, got %v", err)
+	}
+}
+
+func TestClient_Closed(t *testing.T) {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	go func() { _, _ = io.Copy(io.Discard, serverR) }()
+	_ = serverW.Close()
+
+	_, err := newClient(context.Background(), newStreamTransport(clientR, clientW))
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+// TestHelperProcess is not a real test; ConnectStdio runs the test binary
+// with it as a fake MCP server.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("MCP_HELPER_PROCESS") != "1" {
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg serverMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err == nil && msg.ID != nil && msg.Method != "" {
+			_ = enc.Encode(serverReply(msg))
+		}
+	}
+	os.Exit(0)
+}
+
+func TestConnectStdio(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), "MCP_HELPER_PROCESS=1")
+
+	client, err := ConnectStdio(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("ConnectStdio failed: %v", err)
+	}
+	tools, err := client.Tools(context.Background())
+	if err != nil {
+		t.Fatalf("Tools failed: %v", err)
+	}
+	if len(tools) != 2 {
+		t.Errorf("expected 2 tools, got %d", len(tools))
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
+		t.Error("expected server process to exit on Close")
+	}
+}
@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// sessionHeader carries the session ID assigned by the server
+const sessionHeader = "Mcp-Session-Id"
+
+// ConnectHTTP connects to an MCP server using the streamable HTTP
+// transport at endpoint and initializes the connection. If httpClient is
+// nil, http.DefaultClient is used; supply a client with a custom transport
+// to add authentication.
+func ConnectHTTP(ctx context.Context, endpoint string, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return newClient(ctx, &httpTransport{endpoint: endpoint, client: httpClient})
+}
+
+// httpTransport posts every message to the endpoint. Responses arrive
+// either as a JSON body or as a server-sent event stream.
+type httpTransport struct {
+	endpoint string
+	client   *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func (t *httpTransport) post(ctx context.Context, req *rpcRequest) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("mcp http: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	t.mu.Lock()
+	if t.sessionID != "" {
+		httpReq.Header.Set(sessionHeader, t.sessionID)
+	}
+	t.mu.Unlock()
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("mcp http: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("mcp http: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if id := resp.Header.Get(sessionHeader); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+func (t *httpTransport) call(ctx context.Context, req *rpcRequest) (*rpcResponse, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		var msg rpcResponse
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			return nil, fmt.Errorf("mcp http: invalid response: %w", err)
+		}
+		return &msg, nil
+	}
+
+	// Read events until the response to this request arrives
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	var data strings.Builder
+	for {
+		more := scanner.Scan()
+		line := scanner.Text()
+		if v, ok := strings.CutPrefix(line, "data:"); ok && more {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(v, " "))
+			continue
+		}
+
+		// A blank line, or the end of the stream, completes an event
+		if (line == "" || !more) && data.Len() > 0 {
+			if msg := matchResponse(data.String(), *req.ID); msg != nil {
+				return msg, nil
+			}
+			data.Reset()
+		}
+		if !more {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("mcp http: %w", err)
+	}
+	return nil, fmt.Errorf("mcp http: event stream ended without a response to %s", req.Method)
+}
+
+// matchResponse decodes an event and returns it if it is the response
+// with the given ID.
+func matchResponse(data string, id int64) *rpcResponse {
+	var msg rpcResponse
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		return nil
+	}
+	if msg.Method != "" || msg.ID == nil || *msg.ID != id {
+		return nil
+	}
+	return &msg
+}
+
+func (t *httpTransport) notify(ctx context.Context, req *rpcRequest) error {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// close ends the session on the server, if it assigned one.
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.sessionID = ""
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, t.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(sessionHeader, sessionID)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mcp http: %w", err)
+	}
+	return resp.Body.Close()
+}
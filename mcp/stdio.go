@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// maxMessageSize bounds a single newline-delimited message on stdio
+const maxMessageSize = 16 << 20
+
+// ConnectStdio starts cmd as an MCP server speaking over its stdin and
+// stdout and initializes the connection. The command must not have been
+// started. Close stops the server by closing its stdin and waiting for it
+// to exit.
+func ConnectStdio(ctx context.Context, cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp stdio: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("mcp stdio: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("mcp stdio: failed to start server: %w", err)
+	}
+
+	t := newStreamTransport(stdout, stdin)
+	t.wait = cmd.Wait
+	return newClient(ctx, t)
+}
+
+// streamTransport exchanges newline-delimited JSON-RPC messages over a
+// pair of streams, matching responses to requests by ID.
+type streamTransport struct {
+	w    io.WriteCloser
+	wait func() error
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[int64]chan *rpcResponse
+	err     error
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newStreamTransport(r io.Reader, w io.WriteCloser) *streamTransport {
+	t := &streamTransport{
+		w:       w,
+		pending: make(map[int64]chan *rpcResponse),
+	}
+	go t.read(r)
+	return t
+}
+
+// read dispatches incoming messages until the stream ends.
+func (t *streamTransport) read(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var msg rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.Method != "" && msg.ID != nil:
+			t.reply(&msg)
+		case msg.ID != nil:
+			t.mu.Lock()
+			ch, ok := t.pending[*msg.ID]
+			delete(t.pending, *msg.ID)
+			t.mu.Unlock()
+			if ok {
+				ch <- &msg
+			}
+		}
+		// Server notifications are ignored
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	t.mu.Lock()
+	t.err = fmt.Errorf("%w: %w", ErrClosed, err)
+	for id, ch := range t.pending {
+		close(ch)
+		delete(t.pending, id)
+	}
+	t.mu.Unlock()
+}
+
+// reply answers a request from the server. Only ping is supported.
+func (t *streamTransport) reply(req *rpcResponse) {
+	resp := map[string]any{"jsonrpc": "2.0", "id": *req.ID}
+	if req.Method == "ping" {
+		resp["result"] = map[string]any{}
+	} else {
+		resp["error"] = RPCError{Code: -32601, Message: "method not found"}
+	}
+	_ = t.write(resp)
+}
+
+func (t *streamTransport) write(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.w.Write(append(data, '\n'))
+	return err
+}
+
+func (t *streamTransport) call(ctx context.Context, req *rpcRequest) (*rpcResponse, error) {
+	ch := make(chan *rpcResponse, 1)
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return nil, t.err
+	}
+	t.pending[*req.ID] = ch
+	t.mu.Unlock()
+
+	if err := t.write(req); err != nil {
+		t.mu.Lock()
+		delete(t.pending, *req.ID)
+		t.mu.Unlock()
+		return nil, fmt.Errorf("mcp stdio: %w", err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return nil, t.err
+		}
+		return resp, nil
+	case <-ctx.Done():
+		t.mu.Lock()
+		delete(t.pending, *req.ID)
+		t.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (t *streamTransport) notify(_ context.Context, req *rpcRequest) error {
+	return t.write(req)
+}
+
+func (t *streamTransport) close() error {
+	t.closeOnce.Do(func() {
+		t.closeErr = t.w.Close()
+		if t.wait != nil {
+			var exitErr *exec.ExitError
+			if err := t.wait(); err != nil && !errors.As(err, &exitErr) {
+				t.closeErr = err
+			}
+		}
+	})
+	return t.closeErr
+}
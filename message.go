@@ -0,0 +1,69 @@
+package agents
+
+import (
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// MessageText returns the plain text of msg: its content when given as a
+// string, or else its text parts joined with newlines. Images, audio,
+// files and refusals are left out, so guardrails and routers can match
+// against exactly what the user or model wrote.
+func MessageText(msg openai.ChatCompletionMessageParamUnion) string {
+	switch {
+	case msg.OfSystem != nil:
+		c := msg.OfSystem.Content
+		return textOrParts(c.OfString, c.OfArrayOfContentParts)
+	case msg.OfDeveloper != nil:
+		c := msg.OfDeveloper.Content
+		return textOrParts(c.OfString, c.OfArrayOfContentParts)
+	case msg.OfUser != nil:
+		return userText(msg.OfUser, false)
+	case msg.OfAssistant != nil:
+		c := msg.OfAssistant.Content
+		if c.OfString.Valid() {
+			return c.OfString.Value
+		}
+		var texts []string
+		for _, part := range c.OfArrayOfContentParts {
+			if part.OfText != nil {
+				texts = append(texts, part.OfText.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	case msg.OfTool != nil:
+		c := msg.OfTool.Content
+		return textOrParts(c.OfString, c.OfArrayOfContentParts)
+	}
+	return ""
+}
+
+// LastUserText returns the MessageText of the latest user message in
+// history, or "" if there is none.
+func LastUserText(history []openai.ChatCompletionMessageParamUnion) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].OfUser != nil {
+			return MessageText(history[i])
+		}
+	}
+	return ""
+}
+
+// userText joins the text parts of a user message with newlines. With
+// placeholders, parts that are not text appear as e.g. "[image]";
+// otherwise they are left out.
+func userText(user *openai.ChatCompletionUserMessageParam, placeholders bool) string {
+	if user.Content.OfString.Valid() {
+		return user.Content.OfString.Value
+	}
+	texts := make([]string, 0, len(user.Content.OfArrayOfContentParts))
+	for _, part := range user.Content.OfArrayOfContentParts {
+		if part.OfText != nil {
+			texts = append(texts, part.OfText.Text)
+		} else if kind := nonTextPart(part); kind != "" && placeholders {
+			texts = append(texts, "["+kind+"]")
+		}
+	}
+	return strings.Join(texts, "\n")
+}
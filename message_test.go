@@ -0,0 +1,66 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestMessageText(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  openai.ChatCompletionMessageParamUnion
+		want string
+	}{
+		{name: "user string", msg: openai.UserMessage("Hello"), want: "Hello"},
+		{
+			name: "user parts",
+			msg: openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+				openai.TextContentPart("What is in this picture?"),
+				openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: "https://example.com/cat.png"}),
+				openai.TextContentPart("Answer briefly."),
+			}),
+			want: "What is in this picture?\nAnswer briefly.",
+		},
+		{
+			name: "system parts",
+			msg: openai.SystemMessage([]openai.ChatCompletionContentPartTextParam{
+				{Text: "Be kind."}, {Text: "Be brief."},
+			}),
+			want: "Be kind.\nBe brief.",
+		},
+		{name: "assistant", msg: openai.AssistantMessage("Hi there"), want: "Hi there"},
+		{
+			name: "assistant refusal",
+			msg:  openai.ChatCompletionMessageParamUnion{OfAssistant: &openai.ChatCompletionAssistantMessageParam{Refusal: openai.String("No.")}},
+			want: "",
+		},
+		{name: "tool", msg: openai.ToolMessage("sunny", "call_1"), want: "sunny"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageText(tt.msg); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLastUserText(t *testing.T) {
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("first"),
+		openai.AssistantMessage("reply"),
+		openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+			openai.TextContentPart("second"),
+			openai.TextContentPart("question"),
+		}),
+		openai.ToolMessage("result", "call_1"),
+	}
+	if got := LastUserText(history); got != "second\nquestion" {
+		t.Errorf("expected the latest user message, got %q", got)
+	}
+	if got := LastUserText(nil); got != "" {
+		t.Errorf("expected empty text without user messages, got %q", got)
+	}
+}
@@ -0,0 +1,244 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// mockLLM is a local stand-in for the chat completions endpoint. It replies
+// with scripted completions in order and records every request body.
+type mockLLM struct {
+	mu        sync.Mutex
+	responses []string
+	requests  []map[string]any
+	paths     []string
+	headers   []http.Header
+}
+
+// newMockRunner returns a Runner whose client talks to a mockLLM that serves
+// the given completion bodies in order.
+func newMockRunner(t *testing.T, responses ...string) (*Runner, *mockLLM) {
+	t.Helper()
+
+	mock := &mockLLM{responses: responses}
+	srv := httptest.NewServer(http.HandlerFunc(mock.serve))
+	t.Cleanup(srv.Close)
+
+	client := openai.NewClient(
+		option.WithBaseURL(srv.URL),
+		option.WithAPIKey("test-key"),
+		option.WithMaxRetries(0),
+	)
+	return NewRunner(&client), mock
+}
+
+func (m *mockLLM) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req map[string]any
+	_ = json.Unmarshal(body, &req)
+
+	m.mu.Lock()
+	m.requests = append(m.requests, req)
+	m.paths = append(m.paths, r.Method+" "+r.URL.Path)
+	m.headers = append(m.headers, r.Header.Clone())
+	if len(m.responses) == 0 {
+		m.mu.Unlock()
+		http.Error(w, `{"error":{"message":"no scripted response"}}`, http.StatusInternalServerError)
+		return
+	}
+	resp := m.responses[0]
+	m.responses = m.responses[1:]
+	m.mu.Unlock()
+
+	if rest, ok := strings.CutPrefix(resp, errorPrefix); ok {
+		line, body, _ := strings.Cut(rest, "\n")
+		status, retryAfterMs, _ := strings.Cut(line, " ")
+		if retryAfterMs != "" {
+			w.Header().Set("Retry-After-Ms", retryAfterMs)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		code, _ := strconv.Atoi(status)
+		w.WriteHeader(code)
+		_, _ = io.WriteString(w, body)
+		return
+	}
+
+	if strings.HasPrefix(resp, "data:") {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	_, _ = io.WriteString(w, resp)
+}
+
+// Requests returns a copy of the recorded request bodies.
+func (m *mockLLM) Requests() []map[string]any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]map[string]any(nil), m.requests...)
+}
+
+// Headers returns the headers of every recorded request.
+func (m *mockLLM) Headers() []http.Header {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]http.Header(nil), m.headers...)
+}
+
+// Paths returns the "METHOD /path" of every recorded request.
+func (m *mockLLM) Paths() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.paths...)
+}
+
+// mockToolCall describes a tool call to include in a scripted completion.
+type mockToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// fakeChat is a ChatClient that replies with scripted completions in order
+// without going through HTTP, and records every request.
+type fakeChat struct {
+	mu        sync.Mutex
+	responses []string
+	requests  []openai.ChatCompletionNewParams
+}
+
+func (f *fakeChat) New(_ context.Context, body openai.ChatCompletionNewParams, _ ...option.RequestOption) (*openai.ChatCompletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, body)
+	if len(f.responses) == 0 {
+		return nil, errors.New("no scripted response")
+	}
+	var completion openai.ChatCompletion
+	if err := json.Unmarshal([]byte(f.responses[0]), &completion); err != nil {
+		return nil, err
+	}
+	f.responses = f.responses[1:]
+	return &completion, nil
+}
+
+// Requests returns the parameters of every recorded request.
+func (f *fakeChat) Requests() []openai.ChatCompletionNewParams {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]openai.ChatCompletionNewParams(nil), f.requests...)
+}
+
+// errorPrefix marks a scripted error response
+const errorPrefix = "ERROR "
+
+// errorResponse builds a scripted API error with the given status and an
+// optional Retry-After-Ms header.
+func errorResponse(status int, retryAfterMs string) string {
+	return fmt.Sprintf("%s%d %s\n{\"error\":{\"message\":\"status %d\"}}", errorPrefix, status, retryAfterMs, status)
+}
+
+// textCompletion builds a completion body with a plain assistant message.
+func textCompletion(content string) string {
+	return completionJSON(map[string]any{
+		"role":    "assistant",
+		"content": content,
+	}, "stop")
+}
+
+// refusalCompletion builds a completion body in which the model refuses.
+func refusalCompletion(refusal string) string {
+	return completionJSON(map[string]any{
+		"role":    "assistant",
+		"content": nil,
+		"refusal": refusal,
+	}, "stop")
+}
+
+// toolCallCompletion builds a completion body requesting the given tool calls.
+func toolCallCompletion(calls ...mockToolCall) string {
+	toolCalls := make([]map[string]any, 0, len(calls))
+	for i, c := range calls {
+		id := c.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", i)
+		}
+		toolCalls = append(toolCalls, map[string]any{
+			"id":   id,
+			"type": "function",
+			"function": map[string]any{
+				"name":      c.Name,
+				"arguments": c.Arguments,
+			},
+		})
+	}
+	return completionJSON(map[string]any{
+		"role":       "assistant",
+		"content":    nil,
+		"tool_calls": toolCalls,
+	}, "tool_calls")
+}
+
+func completionJSON(message map[string]any, finishReason string) string {
+	data, _ := json.Marshal(map[string]any{
+		"id":      "chatcmpl-test",
+		"object":  "chat.completion",
+		"created": 0,
+		"model":   DefaultModel,
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       message,
+			"finish_reason": finishReason,
+		}},
+		"usage": map[string]any{
+			"prompt_tokens":     10,
+			"completion_tokens": 5,
+			"total_tokens":      15,
+		},
+	})
+	return string(data)
+}
+
+// streamBody builds a server-sent events body from chat completion chunk
+// deltas, followed by a usage-only chunk and the [DONE] marker.
+func streamBody(finishReason string, deltas ...map[string]any) string {
+	var b strings.Builder
+	chunk := func(choices []map[string]any, usage map[string]any) {
+		data, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion.chunk",
+			"created": 0,
+			"model":   DefaultModel,
+			"choices": choices,
+			"usage":   usage,
+		})
+		fmt.Fprintf(&b, "data: %s\n\n", data)
+	}
+
+	for i, delta := range deltas {
+		choice := map[string]any{"index": 0, "delta": delta}
+		if i == len(deltas)-1 {
+			choice["finish_reason"] = finishReason
+		}
+		chunk([]map[string]any{choice}, nil)
+	}
+	chunk([]map[string]any{}, map[string]any{
+		"prompt_tokens":     10,
+		"completion_tokens": 5,
+		"total_tokens":      15,
+	})
+	b.WriteString("data: [DONE]\n\n")
+	return b.String()
+}
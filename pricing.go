@@ -0,0 +1,73 @@
+package agents
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// ModelPricing is the price of a model in US dollars per 1,000 tokens.
+type ModelPricing struct {
+	// Prompt is the price of 1,000 prompt (input) tokens
+	Prompt float64
+
+	// Completion is the price of 1,000 completion (output) tokens
+	Completion float64
+}
+
+// datedSnapshot matches the date suffix of model snapshots like gpt-4o-2024-08-06
+var datedSnapshot = regexp.MustCompile(`-\d{4}-\d{2}-\d{2}$`)
+
+var (
+	pricingMu sync.RWMutex
+
+	// pricing holds list prices for standard (non-batch, uncached) usage.
+	// Prices change; override them with RegisterPricing.
+	pricing = map[string]ModelPricing{
+		ModelGPT4o:      {Prompt: 0.0025, Completion: 0.01},
+		ModelGPT4oMini:  {Prompt: 0.00015, Completion: 0.0006},
+		ModelGPT41:      {Prompt: 0.002, Completion: 0.008},
+		ModelGPT41Mini:  {Prompt: 0.0004, Completion: 0.0016},
+		ModelGPT41Nano:  {Prompt: 0.0001, Completion: 0.0004},
+		ModelO1:         {Prompt: 0.015, Completion: 0.06},
+		ModelO3:         {Prompt: 0.002, Completion: 0.008},
+		ModelO3Mini:     {Prompt: 0.0011, Completion: 0.0044},
+		ModelO4Mini:     {Prompt: 0.0011, Completion: 0.0044},
+		ModelGPT35Turbo: {Prompt: 0.0005, Completion: 0.0015},
+	}
+)
+
+// RegisterPricing sets the price of a model in US dollars per 1,000 prompt
+// and completion tokens, adding a model or overriding a built-in price,
+// e.g. for fine-tuned models or negotiated rates. It is safe to call
+// concurrently with Usage.Cost.
+func RegisterPricing(model string, prompt, completion float64) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	pricing[model] = ModelPricing{Prompt: prompt, Completion: completion}
+}
+
+// PricingFor returns the price of a model. Dated snapshots such as
+// gpt-4o-2024-08-06 fall back to the price of their base model unless
+// they were registered themselves.
+func PricingFor(model string) (ModelPricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+	if p, ok := pricing[model]; ok {
+		return p, true
+	}
+	p, ok := pricing[datedSnapshot.ReplaceAllString(model, "")]
+	return p, ok
+}
+
+// Cost estimates the price of the usage in US dollars for the given model,
+// from its registered per-token prices. It returns ErrUnknownPricing for
+// models without a price; register one with RegisterPricing. Cached-input
+// and batch discounts are not taken into account.
+func (u Usage) Cost(model string) (float64, error) {
+	p, ok := PricingFor(model)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownPricing, model)
+	}
+	return (float64(u.PromptTokens)*p.Prompt + float64(u.CompletionTokens)*p.Completion) / 1000, nil
+}
@@ -0,0 +1,54 @@
+package agents
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestUsageCost(t *testing.T) {
+	RegisterPricing("ft:gpt-4o-mini:acme", 0.0003, 0.0012)
+
+	usage := Usage{PromptTokens: 2000, CompletionTokens: 500, TotalTokens: 2500}
+	tests := []struct {
+		model   string
+		want    float64
+		wantErr bool
+	}{
+		{model: ModelGPT4o, want: 2*0.0025 + 0.5*0.01},
+		{model: ModelGPT4oMini, want: 2*0.00015 + 0.5*0.0006},
+		{model: ModelGPT35Turbo, want: 2*0.0005 + 0.5*0.0015},
+		{model: "gpt-4o-2024-08-06", want: 2*0.0025 + 0.5*0.01},
+		{model: "ft:gpt-4o-mini:acme", want: 2*0.0003 + 0.5*0.0012},
+		{model: "unknown-model", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, err := usage.Cost(tt.model)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownPricing) {
+					t.Errorf("expected ErrUnknownPricing, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Cost failed: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("expected $%f, got $%f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRegisterPricing_Override(t *testing.T) {
+	original, _ := PricingFor(ModelGPT41Nano)
+	t.Cleanup(func() { RegisterPricing(ModelGPT41Nano, original.Prompt, original.Completion) })
+
+	RegisterPricing(ModelGPT41Nano, 1, 2)
+	cost, err := Usage{PromptTokens: 1000, CompletionTokens: 1000}.Cost(ModelGPT41Nano)
+	if err != nil || cost != 3 {
+		t.Errorf("expected overridden cost 3, got %v (%v)", cost, err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package prompt composes agent instructions from reusable, named fragments.
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Separator is placed between composed fragments
+const Separator = "\n\n"
+
+// ErrUnknownFragment is returned when a composed fragment is not registered
+var ErrUnknownFragment = errors.New("unknown prompt fragment")
+
+// ErrMissingVariable is returned when a fragment references a variable
+// that was not provided
+var ErrMissingVariable = errors.New("missing prompt variable")
+
+// placeholder matches {{name}} with optional surrounding spaces.
+var placeholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Library is a set of named prompt fragments, such as shared tone, safety
+// or formatting policies. Fragments may contain {{variable}} placeholders.
+type Library map[string]string
+
+// Compose joins the named fragments in the given order, skipping repeated
+// names, and fills in {{variable}} placeholders from vars. The result can
+// be assigned to Agent.Instructions.
+func (l Library) Compose(vars map[string]string, names ...string) (string, error) {
+	seen := make(map[string]bool, len(names))
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		text, ok := l[name]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownFragment, name)
+		}
+		text, err := Interpolate(text, vars)
+		if err != nil {
+			return "", fmt.Errorf("fragment %s: %w", name, err)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, Separator), nil
+}
+
+// Interpolate replaces {{variable}} placeholders in text with values from
+// vars. It fails with ErrMissingVariable if a placeholder has no value.
+func Interpolate(text string, vars map[string]string) (string, error) {
+	var missing []string
+	out := placeholder.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholder.FindStringSubmatch(match)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingVariable, strings.Join(missing, ", "))
+	}
+	return out, nil
+}
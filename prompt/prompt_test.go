@@ -0,0 +1,85 @@
+package prompt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	lib := Library{
+		"role":   "You are {{ company }}'s support agent.",
+		"tone":   "Be friendly and concise.",
+		"safety": "Never share account passwords.",
+		"empty":  "   ",
+	}
+
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		names   []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "ordered",
+			vars:  map[string]string{"company": "Acme"},
+			names: []string{"role", "tone", "safety"},
+			want:  "You are Acme's support agent.\n\nBe friendly and concise.\n\nNever share account passwords.",
+		},
+		{
+			name:  "deduplicated",
+			names: []string{"safety", "tone", "safety"},
+			want:  "Never share account passwords.\n\nBe friendly and concise.",
+		},
+		{
+			name:  "blank fragments skipped",
+			names: []string{"tone", "empty"},
+			want:  "Be friendly and concise.",
+		},
+		{
+			name:    "unknown fragment",
+			names:   []string{"tone", "legal"},
+			wantErr: ErrUnknownFragment,
+		},
+		{
+			name:    "missing variable",
+			names:   []string{"role"},
+			wantErr: ErrMissingVariable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lib.Compose(tt.vars, tt.names...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Compose failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	got, err := Interpolate("Hi {{name}}, welcome to {{ place }}. {{name}}!", map[string]string{
+		"name":  "Ada",
+		"place": "Go",
+	})
+	if err != nil {
+		t.Fatalf("Interpolate failed: %v", err)
+	}
+	if want := "Hi Ada, welcome to Go. Ada!"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := Interpolate("{{a}} {{b}}", map[string]string{"a": "x"}); !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("expected ErrMissingVariable, got %v", err)
+	}
+}
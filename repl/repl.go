@@ -0,0 +1,122 @@
+// Package repl provides an interactive read-eval-print loop for trying out agents locally.
+package repl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+const (
+	// CommandExit ends the REPL
+	CommandExit = "/exit"
+
+	// CommandClear discards the conversation history
+	CommandClear = "/clear"
+
+	// DefaultPrompt is printed before reading each line
+	DefaultPrompt = "> "
+)
+
+// Options configures a REPL.
+type Options struct {
+	// In is read line by line for user input (default: os.Stdin)
+	In io.Reader
+
+	// Out receives prompts, responses, and usage (default: os.Stdout)
+	Out io.Writer
+
+	// Prompt is printed before each input line (default: DefaultPrompt)
+	Prompt string
+
+	// RunConfig is passed to every Runner.Run call
+	// If nil, the runner's defaults are used
+	RunConfig *agents.RunConfig
+
+	// ContextVariables are shared across all turns of the REPL
+	ContextVariables agents.ContextVariables
+
+	// HideUsage disables the per-turn token usage line
+	HideUsage bool
+}
+
+// Run starts an interactive loop that sends each input line to the agent and
+// prints its reply. The conversation history is kept between turns, and after
+// a handoff the REPL keeps talking to the agent that took over.
+//
+// Supported commands are /clear, which resets the history and agent, and
+// /exit. Run returns nil when the user exits or input ends.
+func Run(ctx context.Context, runner *agents.Runner, agent *agents.Agent, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	in := opts.In
+	if in == nil {
+		in = os.Stdin
+	}
+	out := opts.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = DefaultPrompt
+	}
+	vars := opts.ContextVariables
+	if vars == nil {
+		vars = make(agents.ContextVariables)
+	}
+
+	currentAgent := agent
+	var history []openai.ChatCompletionMessageParamUnion
+	scanner := bufio.NewScanner(in)
+
+	for {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case CommandExit:
+			return nil
+		case CommandClear:
+			history = nil
+			currentAgent = agent
+			fmt.Fprintln(out, "History cleared.")
+			continue
+		}
+
+		messages := append(history[:len(history):len(history)], openai.UserMessage(line))
+		result, err := runner.Run(ctx, currentAgent, messages, vars, opts.RunConfig)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Fprintf(out, "Error: %v\n", err)
+			continue
+		}
+
+		history = result.Messages
+		currentAgent = result.Agent
+
+		fmt.Fprintf(out, "%s: %s\n", currentAgent.Name, result.FinalOutput)
+		if !opts.HideUsage {
+			fmt.Fprintf(out, "[tokens: %d prompt, %d completion, %d total]\n",
+				result.Usage.PromptTokens,
+				result.Usage.CompletionTokens,
+				result.Usage.TotalTokens)
+		}
+	}
+}
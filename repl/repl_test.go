@@ -0,0 +1,99 @@
+package repl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// newTestRunner returns a Runner backed by a local server that echoes the
+// number of messages it received, and a func returning those counts.
+func newTestRunner(t *testing.T) (*agents.Runner, func() []int) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var counts []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []json.RawMessage `json:"messages"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &req)
+
+		mu.Lock()
+		counts = append(counts, len(req.Messages))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"x","object":"chat.completion","created":0,"model":"gpt-4o",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"reply %d"}}],
+			"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`, len(req.Messages))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := openai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+	return agents.NewRunner(&client), func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), counts...)
+	}
+}
+
+func TestRun(t *testing.T) {
+	runner, counts := newTestRunner(t)
+	agent := agents.NewAgent("Bot")
+
+	var out strings.Builder
+	in := strings.NewReader("hello\n\nagain\n/clear\nfresh\n/exit\nignored\n")
+
+	err := Run(context.Background(), runner, agent, &Options{In: in, Out: &out})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// system+user, then system+user+assistant+user, then reset to system+user
+	want := []int{2, 4, 2}
+	if fmt.Sprint(counts()) != fmt.Sprint(want) {
+		t.Errorf("expected message counts %v, got %v", want, counts())
+	}
+
+	output := out.String()
+	for _, s := range []string{"Bot: reply 2", "Bot: reply 4", "History cleared.", "[tokens: 3 prompt, 2 completion, 5 total]"} {
+		if !strings.Contains(output, s) {
+			t.Errorf("expected output to contain %q, got:\n%s", s, output)
+		}
+	}
+}
+
+func TestRunHideUsageAndEOF(t *testing.T) {
+	runner, _ := newTestRunner(t)
+
+	var out strings.Builder
+	err := Run(context.Background(), runner, agents.NewAgent("Bot"), &Options{
+		In:        strings.NewReader("hi"),
+		Out:       &out,
+		Prompt:    "you> ",
+		HideUsage: true,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if strings.Contains(out.String(), "tokens:") {
+		t.Error("expected usage to be hidden")
+	}
+	if !strings.HasPrefix(out.String(), "you> ") {
+		t.Errorf("expected custom prompt, got %q", out.String())
+	}
+}
@@ -0,0 +1,130 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openai/openai-go"
+)
+
+// RecordedTurn is a captured LLM call of a run.
+type RecordedTurn struct {
+	// Request is the chat completion request that was sent
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Completion is the chat completion the model returned
+	Completion json.RawMessage `json:"completion"`
+}
+
+// Recorder captures the LLM calls of runs it is attached to via
+// RunConfig.Recorder, so they can be replayed later with Runner.Replay.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	turns []RecordedTurn
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Turns returns a copy of the recorded turns.
+func (rec *Recorder) Turns() []RecordedTurn {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]RecordedTurn(nil), rec.turns...)
+}
+
+// WriteFile saves the recorded turns to path as JSON.
+func (rec *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(rec.Turns(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// LoadRecording reads turns saved with Recorder.WriteFile.
+func LoadRecording(path string) ([]RecordedTurn, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is chosen by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	var turns []RecordedTurn
+	if err := json.Unmarshal(data, &turns); err != nil {
+		return nil, fmt.Errorf("failed to decode recording: %w", err)
+	}
+	return turns, nil
+}
+
+// wrap returns a completionFunc that records every successful call.
+// A nil Recorder returns complete unchanged.
+func (rec *Recorder) wrap(complete completionFunc) completionFunc {
+	if rec == nil {
+		return complete
+	}
+	return func(ctx context.Context, req openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+		completion, err := complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		turn := RecordedTurn{Completion: json.RawMessage(completion.RawJSON())}
+		if len(turn.Completion) == 0 {
+			// Accumulated stream completions carry no raw JSON
+			if turn.Completion, err = json.Marshal(completion); err != nil {
+				return nil, fmt.Errorf("failed to record completion: %w", err)
+			}
+		}
+		if data, err := json.Marshal(req); err == nil {
+			turn.Request = data
+		}
+
+		rec.mu.Lock()
+		rec.turns = append(rec.turns, turn)
+		rec.mu.Unlock()
+		return completion, nil
+	}
+}
+
+// Replay runs the agent loop like Run, but answers every LLM call with the
+// next recorded completion instead of calling the API. Tools, handoffs and
+// hooks still execute, so a recorded run can be reproduced exactly in tests.
+// If the run needs more LLM calls than were recorded, Replay fails with
+// ErrReplayExhausted.
+func (r *Runner) Replay(
+	ctx context.Context,
+	agent *Agent,
+	messages []openai.ChatCompletionMessageParamUnion,
+	contextParams ContextVariables,
+	config *RunConfig,
+	recorded []RecordedTurn,
+) (*Result, error) {
+	replay := replayCompletion(recorded)
+	return r.run(ctx, agent, messages, contextParams, config, replay, replay)
+}
+
+// replayCompletion returns a completionFunc serving recorded completions in order.
+func replayCompletion(recorded []RecordedTurn) completionFunc {
+	next := 0
+	return func(_ context.Context, _ openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+		if next >= len(recorded) {
+			return nil, fmt.Errorf("%w after %d turns", ErrReplayExhausted, len(recorded))
+		}
+		turn := recorded[next]
+		next++
+
+		var completion openai.ChatCompletion
+		if err := json.Unmarshal(turn.Completion, &completion); err != nil {
+			return nil, fmt.Errorf("failed to decode recorded turn %d: %w", next, err)
+		}
+		return &completion, nil
+	}
+}
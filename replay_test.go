@@ -0,0 +1,97 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestReplay_ReproducesRecordedRun(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "transfer", Arguments: `{}`}),
+		toolCallCompletion(mockToolCall{Name: "lookup", Arguments: `{"id":"42"}`}),
+		textCompletion("order 42 shipped"),
+	)
+
+	var lookups []string
+	newAgents := func() *Agent {
+		second := NewAgent("Orders")
+		second.Tools = []Tool{
+			FunctionTool("lookup", "Look up an order", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+				lookups = append(lookups, args["id"].(string))
+				return "shipped", nil
+			}),
+		}
+		first := NewAgent("Triage")
+		first.Tools = []Tool{
+			FunctionTool("transfer", "Transfer", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+				return second, nil
+			}),
+		}
+		return first
+	}
+
+	recorder := NewRecorder()
+	config := DefaultRunConfig()
+	config.Recorder = recorder
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("where is order 42?")}
+
+	original, err := runner.Run(context.Background(), newAgents(), messages, nil, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if n := len(recorder.Turns()); n != 3 {
+		t.Fatalf("expected 3 recorded turns, got %d", n)
+	}
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	turns, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("LoadRecording failed: %v", err)
+	}
+
+	// The replay runner has no usable client; every call must be served from the recording
+	replayer := NewRunner(&openai.Client{})
+	replayed, err := replayer.Replay(context.Background(), newAgents(), messages, nil, nil, turns)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if replayed.FinalOutput != original.FinalOutput {
+		t.Errorf("expected final output %q, got %q", original.FinalOutput, replayed.FinalOutput)
+	}
+	if replayed.Agent.Name != "Orders" {
+		t.Errorf("expected handoff to Orders, got %s", replayed.Agent.Name)
+	}
+	if replayed.Usage != original.Usage {
+		t.Errorf("expected usage %+v, got %+v", original.Usage, replayed.Usage)
+	}
+	if len(lookups) != 2 || lookups[1] != "42" {
+		t.Errorf("expected the tool to run in both runs, got %v", lookups)
+	}
+}
+
+func TestReplay_Exhausted(t *testing.T) {
+	turns := []RecordedTurn{
+		{Completion: []byte(toolCallCompletion(mockToolCall{Name: "noop", Arguments: `{}`}))},
+	}
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("noop", "Do nothing", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return "ok", nil
+		}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	_, err := NewRunner(&openai.Client{}).Replay(context.Background(), agent, messages, nil, nil, turns)
+	if !errors.Is(err, ErrReplayExhausted) {
+		t.Fatalf("expected ErrReplayExhausted, got %v", err)
+	}
+}
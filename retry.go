@@ -0,0 +1,87 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// DefaultRetryBackoff is the first retry delay when RunConfig.RetryBackoff is 0
+const DefaultRetryBackoff = 500 * time.Millisecond
+
+// retryCompletion returns a completionFunc that retries transient failures
+// of complete up to maxRetries times with exponential backoff.
+func retryCompletion(complete completionFunc, maxRetries int, backoff time.Duration) completionFunc {
+	if maxRetries <= 0 {
+		return complete
+	}
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+
+	return func(ctx context.Context, req openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+		delay := backoff
+		for attempt := 1; ; attempt++ {
+			completion, err := complete(ctx, req)
+			if err == nil || attempt > maxRetries || !isTransient(ctx, err) {
+				return completion, err
+			}
+
+			// Wait between half and all of the current delay
+			wait := delay/2 + rand.N(delay/2+1)
+			if hint, ok := RetryAfter(err); ok {
+				wait = hint
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return nil, err
+			}
+
+			LoggerFromContext(ctx).Warn("retrying LLM call", "attempt", attempt, "wait", wait, "error", err)
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, err
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+	}
+}
+
+// isTransient reports whether a failed LLM call may succeed when retried.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		// Without a status only transport failures are worth retrying;
+		// errors such as ErrReplayExhausted or a failing stream writer
+		// would fail the same way again
+		return isTransportError(err)
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return true
+	}
+	return apiErr.StatusCode >= http.StatusInternalServerError
+}
+
+// isTransportError reports whether err is a network failure, such as a
+// reset connection or a response cut off mid-body.
+func isTransportError(err error) bool {
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
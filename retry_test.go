@@ -0,0 +1,137 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+func TestRun_RetryTransientErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		responses    []string
+		maxRetries   int
+		wantRequests int
+		wantStatus   int
+	}{
+		{
+			name:         "recovers after rate limits",
+			responses:    []string{errorResponse(429, "1"), errorResponse(503, ""), textCompletion("ok")},
+			maxRetries:   3,
+			wantRequests: 3,
+		},
+		{
+			name:         "retries exhausted",
+			responses:    []string{errorResponse(500, ""), errorResponse(502, ""), textCompletion("ok")},
+			maxRetries:   1,
+			wantRequests: 2,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "auth errors are not retried",
+			responses:    []string{errorResponse(401, ""), textCompletion("ok")},
+			maxRetries:   3,
+			wantRequests: 1,
+			wantStatus:   http.StatusUnauthorized,
+		},
+		{
+			name:         "disabled by default",
+			responses:    []string{errorResponse(429, ""), textCompletion("ok")},
+			wantRequests: 1,
+			wantStatus:   http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, mock := newMockRunner(t, tt.responses...)
+			config := &RunConfig{MaxTurns: 1, MaxRetries: tt.maxRetries, RetryBackoff: time.Millisecond}
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+			result, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, config)
+			if got := len(mock.Requests()); got != tt.wantRequests {
+				t.Errorf("expected %d requests, got %d", tt.wantRequests, got)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil || result.FinalOutput != "ok" {
+					t.Fatalf("expected success, got %v", err)
+				}
+				return
+			}
+			var apiErr *openai.Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %v", tt.wantStatus, err)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "rate limit", err: &openai.Error{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "bad request", err: &openai.Error{StatusCode: http.StatusBadRequest}},
+		{name: "connection reset", err: &url.Error{Op: "Post", URL: "https://api.openai.com", Err: syscall.ECONNRESET}, want: true},
+		{name: "truncated body", err: fmt.Errorf("reading response: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "replay exhausted", err: fmt.Errorf("%w after 1 turns", ErrReplayExhausted)},
+		{name: "other error", err: errors.New("stream writer closed")},
+		{name: "cancelled", err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(context.Background(), tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplay_ExhaustedIsNotRetried(t *testing.T) {
+	turns := []RecordedTurn{{Completion: []byte(toolCallCompletion(mockToolCall{Name: "noop", Arguments: `{}`}))}}
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("noop", "Do nothing", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return "ok", nil
+		}),
+	}
+	config := &RunConfig{MaxRetries: 3, RetryBackoff: time.Second}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	start := time.Now()
+	_, err := NewRunner(&openai.Client{}).Replay(context.Background(), agent, messages, nil, config, turns)
+	if !errors.Is(err, ErrReplayExhausted) {
+		t.Fatalf("expected ErrReplayExhausted, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the first attempt to fail the run, took %v", elapsed)
+	}
+}
+
+func TestRetryCompletion_RespectsDeadline(t *testing.T) {
+	runner, mock := newMockRunner(t, errorResponse(429, "60000"), textCompletion("ok"))
+	config := &RunConfig{MaxTurns: 1, MaxRetries: 3, Timeout: time.Second}
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+	start := time.Now()
+	if _, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, config); err == nil {
+		t.Fatal("expected error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("expected the run to give up instead of waiting past its deadline")
+	}
+	if len(mock.Requests()) != 1 {
+		t.Errorf("expected no retry, got %d requests", len(mock.Requests()))
+	}
+}
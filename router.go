@@ -0,0 +1,29 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// route follows the Router of agent and of every agent it routes to,
+// returning the agent that should handle the run. It stops when a router
+// returns nil or an agent that was already visited.
+func route(ctx context.Context, agent *Agent, history []openai.ChatCompletionMessageParamUnion) (*Agent, error) {
+	input := LastUserText(history)
+	visited := map[*Agent]bool{agent: true}
+	for agent.Router != nil {
+		next, err := agent.Router(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("router of agent %s failed: %w", agent.Name, err)
+		}
+		if next == nil || visited[next] {
+			break
+		}
+		LoggerFromContext(ctx).Debug("routed to agent", "from", agent.Name, "to", next.Name)
+		visited[next] = true
+		agent = next
+	}
+	return agent, nil
+}
@@ -0,0 +1,83 @@
+package agents
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestRun_Router(t *testing.T) {
+	runner, mock := newMockRunner(t, textCompletion("refund issued"))
+
+	refunds := NewAgent("Refunds")
+	refunds.Instructions = "You handle refunds."
+	billing := NewAgent("Billing")
+	billing.Router = func(_ context.Context, input string) (*Agent, error) {
+		if strings.Contains(input, "refund") {
+			return refunds, nil
+		}
+		return nil, nil
+	}
+	triage := NewAgent("Triage")
+	var routedInput string
+	triage.Router = func(_ context.Context, input string) (*Agent, error) {
+		routedInput = input
+		return billing, nil
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("hello"),
+		openai.AssistantMessage("How can I help?"),
+		openai.UserMessage("I want a refund"),
+	}
+	result, err := runner.Run(context.Background(), triage, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if routedInput != "I want a refund" {
+		t.Errorf("expected router to see the latest user message, got %q", routedInput)
+	}
+	if result.Agent != refunds || result.Steps[0].AgentName != "Refunds" {
+		t.Errorf("expected run to be handled by Refunds, got %s", result.Agent.Name)
+	}
+	sent := mock.Requests()[0]["messages"].([]any)
+	if sent[0].(map[string]any)["content"] != "You handle refunds." {
+		t.Errorf("expected routed agent's instructions, got %v", sent[0])
+	}
+}
+
+func TestRun_RouterCycle(t *testing.T) {
+	runner, _ := newMockRunner(t, textCompletion("ok"))
+
+	a, b := NewAgent("A"), NewAgent("B")
+	a.Router = func(context.Context, string) (*Agent, error) { return b, nil }
+	b.Router = func(context.Context, string) (*Agent, error) { return a, nil }
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	result, err := runner.Run(context.Background(), a, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Agent != b {
+		t.Errorf("expected routing to stop at B, got %s", result.Agent.Name)
+	}
+}
+
+func TestRun_RouterError(t *testing.T) {
+	runner, mock := newMockRunner(t)
+
+	errClassifier := errors.New("classifier unavailable")
+	agent := NewAgent("Triage")
+	agent.Router = func(context.Context, string) (*Agent, error) { return nil, errClassifier }
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	if _, err := runner.Run(context.Background(), agent, messages, nil, nil); !errors.Is(err, errClassifier) {
+		t.Fatalf("expected router error, got %v", err)
+	}
+	if len(mock.Requests()) != 0 {
+		t.Error("expected no LLM call after a router error")
+	}
+}
@@ -2,33 +2,127 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
 
 	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
 // Runner manages the execution of agents.
+//
+// A Runner is safe for concurrent use, so a service can create one and
+// share it across goroutines. Apart from the registry of runs started with
+// RunWithID it holds no per-run state. Agents and
+// RunConfigs are only read during a run and may be shared as well. The
+// ContextVariables passed to Run are written by tools, so each concurrent
+// run needs its own map; seeds in RunConfig.ContextVariables are copied
+// into it and never modified.
 type Runner struct {
+	// Client serves streaming, Ping and the Files API
 	Client *openai.Client
+
+	// Chat creates the chat completions of non-streaming runs. NewRunner
+	// sets it to the client's chat completions service; replace it to wrap
+	// that service, e.g. with NewAzureChatClient.
+	Chat ChatClient
+
+	// active maps run IDs to handles of runs started with RunWithID
+	active sync.Map
 }
 
 // NewRunner creates a new Runner.
 func NewRunner(client *openai.Client) *Runner {
-	return &Runner{
+	r := &Runner{
 		Client: client,
 	}
+	if client != nil {
+		r.Chat = &client.Chat.Completions
+	}
+	return r
+}
+
+// NewRunnerWithClient creates a Runner whose runs create completions
+// through chat, such as a scripted fake in tests. The Runner has no Client,
+// so streaming, Ping and the Files API return ErrNoClient.
+func NewRunnerWithClient(chat ChatClient) *Runner {
+	return &Runner{
+		Chat: chat,
+	}
+}
+
+// ChatClient creates chat completions. The Chat.Completions service of an
+// openai.Client implements it.
+type ChatClient interface {
+	New(ctx context.Context, body openai.ChatCompletionNewParams, opts ...option.RequestOption) (*openai.ChatCompletion, error)
+}
+
+// Ping checks that the API is reachable and the credentials are accepted by
+// listing the available models, which costs no tokens. It is intended as a
+// readiness probe; the returned error wraps the underlying *openai.Error for
+// HTTP failures such as an invalid API key.
+func (r *Runner) Ping(ctx context.Context) error {
+	if r.Client == nil {
+		return ErrNoClient
+	}
+	if _, err := r.Client.Models.List(ctx); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
 }
 
+// completionFunc performs a single LLM call of the agent loop.
+type completionFunc func(ctx context.Context, req openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)
+
 // Run executes the agent loop with the given configuration.
+//
+// The messages may contain any role the chat completions API accepts, and
+// they are sent to the model unchanged and in order. A system or developer
+// message in the history takes the place of the agent's instructions.
+// Assistant messages, for example few-shot examples, are treated as history
+// only. FinalOutput always comes from a reply the model produced during
+// this run and never from a message the caller passed in.
 func (r *Runner) Run(
 	ctx context.Context,
 	agent *Agent,
 	messages []openai.ChatCompletionMessageParamUnion,
 	contextParams ContextVariables,
 	config *RunConfig,
+) (*Result, error) {
+	return r.run(ctx, agent, messages, contextParams, config, r.newCompletion, r.newCompletion)
+}
+
+// newCompletion calls the chat completions API without streaming.
+func (r *Runner) newCompletion(ctx context.Context, req openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	switch {
+	case r.Chat != nil:
+		return r.Chat.New(ctx, req, requestOptions(ctx)...)
+	case r.Client != nil:
+		return r.Client.Chat.Completions.New(ctx, req, requestOptions(ctx)...)
+	}
+	return nil, ErrNoClient
+}
+
+// run is the agent loop shared by Run and the streaming variants. The
+// agent's turns use complete; summaries of tool results use summarize,
+// which never streams, so they stay out of a streaming run's output.
+func (r *Runner) run(
+	ctx context.Context,
+	agent *Agent,
+	messages []openai.ChatCompletionMessageParamUnion,
+	contextParams ContextVariables,
+	config *RunConfig,
+	complete, summarize completionFunc,
 ) (*Result, error) {
 	if len(messages) == 0 {
 		return nil, ErrNoMessages
@@ -46,11 +140,27 @@ func (r *Runner) Run(
 		defer cancel()
 	}
 
-	// Initialize context variables
-	if contextParams == nil {
-		contextParams = make(ContextVariables)
+	complete = config.Recorder.wrap(complete)
+	complete = retryCompletion(complete, config.MaxRetries, config.RetryBackoff)
+	summarize = config.Recorder.wrap(summarize)
+	summarize = retryCompletion(summarize, config.MaxRetries, config.RetryBackoff)
+
+	// Attach run ID and logger for correlation across tools and hooks
+	ctx, runID := claimRunID(ctx)
+	ctx = withRunContext(ctx, runID, config.Logger)
+	ctx = withAPIKey(ctx, config.APIKey)
+	ctx, events := withRunEvents(ctx, runID, config.EventSink)
+	logger := LoggerFromContext(ctx)
+
+	// Clean up files after the run, even if it is cancelled
+	if len(config.DeleteFiles) > 0 {
+		defer r.deleteFiles(context.WithoutCancel(ctx), config.DeleteFiles)
 	}
 
+	// Initialize context variables; the same map is shared by every agent
+	// and tool in the run, so state accumulates across turns and handoffs
+	contextParams = seedContextVariables(contextParams, config.ContextVariables)
+
 	// Execute OnBeforeRun hook
 	if agent.OnBeforeRun != nil {
 		if err := agent.OnBeforeRun(ctx, agent); err != nil {
@@ -58,71 +168,113 @@ func (r *Runner) Run(
 		}
 	}
 
-	currentAgent := agent
 	history := make([]openai.ChatCompletionMessageParamUnion, len(messages))
 	copy(history, messages)
 
+	// Let routers pick the handling agent before the first LLM call
+	currentAgent, err := route(ctx, agent, history)
+	if err != nil {
+		return nil, err
+	}
+
 	var usage Usage
 	var steps []Step
 	var lastMessage openai.ChatCompletionMessage
+	var lastFinishReason string
 	turnCount := 0
+	toolCallCount := 0
+	agentCalledTools := false
+	cache := make(toolResultCache)
+
+	// buildResult snapshots the run state; early exits return it as a partial result
+	buildResult := func(reason StopReason) *Result {
+		result := &Result{
+			RunID:         runID,
+			Messages:      history,
+			Turns:         buildTurns(history, steps),
+			Agent:         currentAgent,
+			Usage:         usage,
+			Steps:         steps,
+			ToolCallCount: toolCallCount,
+			StopReason:    reason,
+		}
+		if len(steps) > 0 {
+			result.ResponseID = steps[len(steps)-1].ResponseID
+		}
+		events.emit(EventRunEnd, currentAgent, map[string]any{"stop_reason": reason, "usage": usage})
+		return result
+	}
 
 	for {
 		// Check max turns
 		if config.MaxTurns > 0 && turnCount >= config.MaxTurns {
-			return nil, ErrMaxTurnsExceeded
+			return buildResult(StopReasonMaxTurns), ErrMaxTurnsExceeded
 		}
 
 		// Check context cancellation (timeout)
-		if err := ctx.Err(); err != nil {
-			if err == context.DeadlineExceeded {
-				return nil, ErrTimeout
-			}
-			return nil, err
+		if ctx.Err() != nil {
+			reason, err := contextStop(ctx)
+			return buildResult(reason), err
 		}
 
 		stepStart := time.Now()
 		turnCount++
+		events.setTurn(turnCount)
 
-		// Prepare tools
-		var tools []openai.ChatCompletionToolParam
-		toolMap := make(map[string]Tool)
-		for _, t := range currentAgent.Tools {
-			tools = append(tools, t.ToParam())
-			toolMap[t.Name] = t
+		// Check the history before it is sent to the model
+		if safeResponse, err := checkTurnGuardrails(ctx, currentAgent, config, history, contextParams, turnCount); err != nil {
+			if safeResponse == "" {
+				return buildResult(StopReasonGuardrail), err
+			}
+			logger.Warn("guardrail tripped, sending safe response", "error", err)
+			history = append(history, openai.AssistantMessage(safeResponse))
+			result := buildResult(StopReasonGuardrail)
+			result.FinalOutput = safeResponse
+			return result, nil
 		}
 
-		// Prepare request
-		req, err := r.prepareRequest(ctx, currentAgent, config, tools, history)
+		req, toolMap, err := r.turnRequest(ctx, currentAgent, config, history, contextParams, agentCalledTools)
 		if err != nil {
 			return nil, err
 		}
 
 		// Call OpenAI
-		completion, err := r.Client.Chat.Completions.New(ctx, req)
+		logger.Debug("calling LLM", "agent", currentAgent.Name, "turn", turnCount)
+		events.emit(EventLLMRequest, currentAgent, map[string]any{
+			"model":    req.Model,
+			"messages": len(req.Messages),
+			"tools":    len(req.Tools),
+		})
+		completion, err := complete(ctx, req)
+		if err == nil && len(completion.Choices) == 0 {
+			err = ErrNoChoices
+		}
 		if err != nil {
+			events.emit(EventLLMResponse, currentAgent, map[string]any{"error": err.Error()})
+			// A call interrupted by the run context ends the run like a
+			// turn boundary would, keeping the partial result
+			if ctx.Err() != nil {
+				reason, ctxErr := contextStop(ctx)
+				return buildResult(reason), ctxErr
+			}
 			return nil, fmt.Errorf("LLM call failed: %w", err)
 		}
+		events.emit(EventLLMResponse, currentAgent, map[string]any{
+			"response_id":   completion.ID,
+			"finish_reason": completion.Choices[0].FinishReason,
+			"tool_calls":    len(completion.Choices[0].Message.ToolCalls),
+			"usage":         completionUsage(completion),
+		})
 
 		// Track usage
-		if completion.Usage.PromptTokens > 0 {
-			usage.Add(Usage{
-				PromptTokens:     int(completion.Usage.PromptTokens),
-				CompletionTokens: int(completion.Usage.CompletionTokens),
-				TotalTokens:      int(completion.Usage.TotalTokens),
-			})
-		}
+		stepUsage := completionUsage(completion)
+		usage.Add(stepUsage)
 
 		message := completion.Choices[0].Message
+		lastFinishReason = completion.Choices[0].FinishReason
 
 		// Truncate tool call IDs in the assistant message if needed
-		if len(message.ToolCalls) > 0 {
-			for i := range message.ToolCalls {
-				if len(message.ToolCalls[i].ID) > 40 {
-					message.ToolCalls[i].ID = message.ToolCalls[i].ID[:40]
-				}
-			}
-		}
+		truncateToolCallIDs(message.ToolCalls)
 
 		history = append(history, message.ToParam())
 
@@ -131,6 +283,8 @@ func (r *Runner) Run(
 			AgentName:  currentAgent.Name,
 			StepNumber: turnCount,
 			Duration:   time.Since(stepStart),
+			Usage:      stepUsage,
+			ResponseID: completion.ID,
 		}
 
 		// Check for tool calls
@@ -138,41 +292,46 @@ func (r *Runner) Run(
 			// No tools called, save the final message and exit
 			lastMessage = message
 			steps = append(steps, step)
+			events.emit(EventStepComplete, currentAgent, stepEventData(step))
 			break
 		}
 
+		if reason, err := checkToolCallLimits(currentAgent, config, toolMap, toolCallCount, message.ToolCalls); err != nil {
+			steps = append(steps, step)
+			return buildResult(reason), err
+		}
+		toolCallCount += len(message.ToolCalls)
+
 		// Handle Tool Calls
-		toolMessages, recordedToolCalls, nextAgent := r.handleToolCalls(message.ToolCalls, toolMap, contextParams, currentAgent)
+		toolMessages, recordedToolCalls, handoff, toolUsage := r.handleToolCalls(ctx, message.ToolCalls, toolMap, cache, contextParams, currentAgent, config, summarize)
+		usage.Add(toolUsage)
+		step.Usage.Add(toolUsage)
 
 		step.ToolCalls = recordedToolCalls
 		history = append(history, toolMessages...)
+		agentCalledTools = true
 
-		if nextAgent != nil {
-			currentAgent = nextAgent
+		if handoff != nil {
+			agentCalledTools = handoff.Agent == currentAgent
+			history = handoff.apply(ctx, currentAgent, history)
+			currentAgent = handoff.Agent
 		}
 
 		step.Duration = time.Since(stepStart)
 		steps = append(steps, step)
+		events.emit(EventStepComplete, currentAgent, stepEventData(step))
 
-		// Continue loop
-	}
-
-	// Extract final output
-	finalOutput := ""
-	if len(history) > 0 {
-		if len(lastMessage.Content) > 0 {
-			finalOutput = lastMessage.Content
-		} else if lastMessage.Refusal != "" {
-			finalOutput = lastMessage.Refusal
+		// Stop before another LLM call once the token budget is spent
+		if config.MaxTotalTokens > 0 && usage.TotalTokens > config.MaxTotalTokens {
+			return buildResult(StopReasonTokenBudget), ErrTokenBudgetExceeded
 		}
+
+		// Continue loop
 	}
 
-	result := &Result{
-		Messages:    history,
-		Agent:       currentAgent,
-		Usage:       usage,
-		Steps:       steps,
-		FinalOutput: finalOutput,
+	result, err := completeRun(ctx, currentAgent, config, lastMessage, lastFinishReason, contextParams, buildResult)
+	if err != nil {
+		return result, err
 	}
 
 	// Execute OnAfterRun hook
@@ -185,6 +344,149 @@ func (r *Runner) Run(
 	return result, nil
 }
 
+// seedContextVariables returns the run's context variables, adding the
+// seeds that vars does not set. A nil vars is replaced by a new map.
+func seedContextVariables(vars, seeds ContextVariables) ContextVariables {
+	if vars == nil {
+		vars = make(ContextVariables)
+	}
+	for k, v := range seeds {
+		if _, exists := vars[k]; !exists {
+			vars[k] = v
+		}
+	}
+	return vars
+}
+
+// contextStop returns the stop reason and error of a run whose context
+// has ended.
+func contextStop(ctx context.Context) (StopReason, error) {
+	err := ctx.Err()
+	if err == context.DeadlineExceeded {
+		return StopReasonTimeout, ErrTimeout
+	}
+	return StopReasonCancelled, err
+}
+
+// turnRequest prepares the request for a turn of agent and returns it with
+// the agent's tools by name. calledTools reports whether the agent has
+// called tools earlier in the run.
+func (r *Runner) turnRequest(
+	ctx context.Context,
+	agent *Agent,
+	config *RunConfig,
+	history []openai.ChatCompletionMessageParamUnion,
+	vars ContextVariables,
+	calledTools bool,
+) (openai.ChatCompletionNewParams, map[string]Tool, error) {
+	toolMap, err := agent.toolMap()
+	if err != nil {
+		return openai.ChatCompletionNewParams{}, nil, err
+	}
+
+	req, err := r.prepareRequest(ctx, agent, config, agent.ToolSchemas(), history)
+	if err != nil {
+		return req, nil, err
+	}
+	// A forced tool call has happened, let the model answer
+	if calledTools && forcesToolCall(req.ToolChoice) {
+		req.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{}
+	}
+	if note := vars.Scratchpad(); config.InjectScratchpad && note != "" {
+		req.Messages = append(req.Messages, openai.SystemMessage(scratchpadPrefix+note))
+	}
+	return req, toolMap, nil
+}
+
+// checkToolCallLimits returns the stop reason and error of a run whose
+// agent requested calls it must not run, or a nil error if they may run.
+func checkToolCallLimits(
+	agent *Agent,
+	config *RunConfig,
+	toolMap map[string]Tool,
+	executed int,
+	calls []openai.ChatCompletionMessageToolCall,
+) (StopReason, error) {
+	// A tool call on a tool-less agent would only loop on "not found"
+	// errors until MaxTurns, so stop right away
+	if len(toolMap) == 0 {
+		return StopReasonUnexpectedToolCall, fmt.Errorf("%w: agent %s called %s",
+			ErrUnexpectedToolCall, agent.Name, calls[0].Function.Name)
+	}
+
+	// Refuse the whole batch rather than run part of it
+	if config.MaxToolCalls > 0 && executed+len(calls) > config.MaxToolCalls {
+		return StopReasonMaxToolCalls, fmt.Errorf("%w: %d executed, %d more requested, limit %d",
+			ErrMaxToolCallsExceeded, executed, len(calls), config.MaxToolCalls)
+	}
+	return "", nil
+}
+
+// completeRun checks the final output of a run whose agent answered with
+// message and returns the result, applying the refusal policy when the
+// model declined to answer.
+func completeRun(
+	ctx context.Context,
+	agent *Agent,
+	config *RunConfig,
+	message openai.ChatCompletionMessage,
+	finishReason string,
+	vars ContextVariables,
+	buildResult func(StopReason) *Result,
+) (*Result, error) {
+	output := finalOutput(message)
+	refused := message.Content == "" && message.Refusal != ""
+
+	// Check the final output before the run is reported complete
+	if !refused {
+		if err := checkFinalOutputGuardrails(ctx, agent, config, output, vars); err != nil {
+			result := buildResult(StopReasonGuardrail)
+			result.FinalOutput = output
+			result.ResponseFormat = resolveResponseFormat(agent, config)
+			return result, err
+		}
+	}
+
+	result := buildResult(StopReasonCompleted)
+	result.FinalOutput = output
+	result.Truncated = finishReason == finishReasonLength
+	result.ResponseFormat = resolveResponseFormat(agent, config)
+	if !refused {
+		return result, nil
+	}
+
+	result.Refusal = message.Refusal
+	if config.RefusalFallback != "" {
+		result.FinalOutput = config.RefusalFallback
+	}
+	if config.OnRefusal != nil {
+		if err := config.OnRefusal(ctx, agent, message.Refusal); err != nil {
+			return result, fmt.Errorf("OnRefusal hook failed: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// applySamplingSettings sets the sampling parameters of req: nucleus
+// sampling, the penalties, the seed and the stop sequences.
+func applySamplingSettings(req *openai.ChatCompletionNewParams, agent *Agent, config *RunConfig) {
+	if v := resolveSetting(config, config.TopP, agent.TopP); v != nil {
+		req.TopP = openai.Float(*v)
+	}
+	if v := resolveSetting(config, config.FrequencyPenalty, agent.FrequencyPenalty); v != nil {
+		req.FrequencyPenalty = openai.Float(*v)
+	}
+	if v := resolveSetting(config, config.PresencePenalty, agent.PresencePenalty); v != nil {
+		req.PresencePenalty = openai.Float(*v)
+	}
+	if v := resolveSetting(config, config.Seed, agent.Seed); v != nil {
+		req.Seed = openai.Int(*v)
+	}
+	if stop := resolveStop(agent, config); len(stop) > 0 {
+		req.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: stop}
+	}
+}
+
 func (r *Runner) prepareRequest(
 	ctx context.Context,
 	agent *Agent,
@@ -197,38 +499,52 @@ func (r *Runner) prepareRequest(
 	}
 
 	// Apply model settings
-	if config.Temperature != nil {
-		req.Temperature = openai.Float(*config.Temperature)
-	} else if agent.Temperature != nil {
-		req.Temperature = openai.Float(*agent.Temperature)
+	if v := resolveSetting(config, config.Temperature, agent.Temperature); v != nil {
+		req.Temperature = openai.Float(*v)
+	}
+	if v := resolveSetting(config, config.MaxTokens, agent.MaxTokens); v != nil {
+		req.MaxTokens = openai.Int(int64(*v))
 	}
+	applySamplingSettings(&req, agent, config)
 
-	if config.MaxTokens != nil {
-		req.MaxTokens = openai.Int(int64(*config.MaxTokens))
-	} else if agent.MaxTokens != nil {
-		req.MaxTokens = openai.Int(int64(*agent.MaxTokens))
+	if config.StoreResponses {
+		req.Store = openai.Bool(true)
+	}
+	if len(config.Metadata) > 0 {
+		req.Metadata = shared.Metadata(config.Metadata)
+	}
+	if config.EndUserID != "" {
+		req.User = openai.String(config.EndUserID)
 	}
 
+	logitBias, err := resolveLogitBias(agent, config)
+	if err != nil {
+		return req, err
+	}
+	req.LogitBias = logitBias
+
 	if len(tools) > 0 {
 		req.Tools = tools
-		parallelCalls := agent.ParallelToolCalls
-		if config.ParallelToolCalls != nil {
-			parallelCalls = *config.ParallelToolCalls
-		}
-		if !parallelCalls {
-			req.ParallelToolCalls = openai.Bool(false)
-		}
+		// Always send the resolved value so behavior doesn't depend on provider defaults
+		req.ParallelToolCalls = openai.Bool(parallelToolCalls(agent, config))
+	}
+	toolChoice, err := resolveToolChoice(agent, config, tools)
+	if err != nil {
+		return req, err
 	}
+	req.ToolChoice = toolChoice
 
-	// Apply response format
-	var responseFormat *jsonschema.ResponseFormat
-	if config.ResponseFormat != nil {
-		responseFormat = config.ResponseFormat
-	} else if agent.ResponseFormat != nil {
-		responseFormat = agent.ResponseFormat
+	// Predicted outputs are only supported by some models and not with tools
+	if config.PredictedOutput != "" && len(tools) == 0 && supportsPrediction(agent.Model) {
+		req.Prediction = openai.ChatCompletionPredictionContentParam{
+			Content: openai.ChatCompletionPredictionContentContentUnionParam{
+				OfString: openai.String(config.PredictedOutput),
+			},
+		}
 	}
 
-	if responseFormat != nil {
+	// Apply response format
+	if responseFormat := resolveResponseFormat(agent, config); responseFormat != nil {
 		if responseFormat.Type == "text" {
 			req.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
 				OfText: &openai.ResponseFormatTextParam{
@@ -245,7 +561,7 @@ func (r *Runner) prepareRequest(
 			params := openai.ResponseFormatJSONSchemaJSONSchemaParam{
 				Name:   js.Name,
 				Schema: schemaMap,
-				Strict: openai.Bool(js.Strict),
+				Strict: openai.Bool(js.Strict && !config.DisableStrictOutput),
 			}
 			if js.Description != "" {
 				params.Description = openai.String(js.Description)
@@ -260,75 +576,609 @@ func (r *Runner) prepareRequest(
 		}
 	}
 
-	// Inject system instructions
+	// Inject system instructions unless suppressed, empty, or already supplied
+	var messagesForTurn []openai.ChatCompletionMessageParamUnion
 	instructions := agent.GetInstructions(ctx)
-	messagesForTurn := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage(instructions),
+	if !config.SuppressSystemMessage && strings.TrimSpace(instructions) != "" && !hasSystemMessage(history) {
+		messagesForTurn = append(messagesForTurn, openai.SystemMessage(instructions))
+	}
+	// Few-shot examples go after any leading system messages of the history
+	window := windowHistory(history, config.MaxHistoryMessages, config.MaxHistoryTokens)
+	leading := 0
+	for leading < len(window) && (window[leading].OfSystem != nil || window[leading].OfDeveloper != nil) {
+		leading++
 	}
-	messagesForTurn = append(messagesForTurn, history...)
+	messagesForTurn = append(messagesForTurn, window[:leading]...)
+	for _, ex := range agent.Examples {
+		messagesForTurn = append(messagesForTurn, openai.UserMessage(ex.User), openai.AssistantMessage(ex.Assistant))
+	}
+	messagesForTurn = append(messagesForTurn, window[leading:]...)
 	req.Messages = messagesForTurn
 
 	return req, nil
 }
 
+// windowHistory returns the system and developer messages of history plus
+// its last maxMessages other messages, further trimmed from the front until
+// the estimated size fits maxTokens. A window limited by message count that
+// would start with tool results is widened back to the assistant message
+// that requested them; one limited by size drops those results instead.
+func windowHistory(history []openai.ChatCompletionMessageParamUnion, maxMessages, maxTokens int) []openai.ChatCompletionMessageParamUnion {
+	if maxMessages <= 0 && maxTokens <= 0 {
+		return history
+	}
+
+	var pinned, rest []openai.ChatCompletionMessageParamUnion
+	for _, msg := range history {
+		if msg.OfSystem != nil || msg.OfDeveloper != nil {
+			pinned = append(pinned, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+
+	start := 0
+	if maxMessages > 0 && len(rest) > maxMessages {
+		start = len(rest) - maxMessages
+		for start > 0 && rest[start].OfTool != nil {
+			start--
+		}
+	}
+
+	if maxTokens > 0 && start < len(rest) {
+		tokens := 0
+		for _, msg := range pinned {
+			tokens += estimateTokens(msg)
+		}
+		i := len(rest)
+		for i > start && tokens+estimateTokens(rest[i-1]) <= maxTokens {
+			i--
+			tokens += estimateTokens(rest[i])
+		}
+		for i < len(rest) && rest[i].OfTool != nil {
+			i++
+		}
+		// Nothing fits: keep the latest message, with its tool call if any
+		if i == len(rest) {
+			i--
+			for i > start && rest[i].OfTool != nil {
+				i--
+			}
+		}
+		start = i
+	}
+
+	if start == 0 {
+		return history
+	}
+	return append(pinned, rest[start:]...)
+}
+
+// estimateTokens approximates the tokens a message takes up in a request
+// from the length of its JSON encoding.
+func estimateTokens(msg openai.ChatCompletionMessageParamUnion) int {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + 3) / 4
+}
+
+// resolveLogitBias merges the agent's and the config's logit bias, with the
+// config winning per token, and validates the bias values.
+func resolveLogitBias(agent *Agent, config *RunConfig) (map[string]int64, error) {
+	if len(agent.LogitBias) == 0 && len(config.LogitBias) == 0 {
+		return nil, nil
+	}
+
+	merged := make(map[string]int64, len(agent.LogitBias)+len(config.LogitBias))
+	for _, bias := range []map[int]int{agent.LogitBias, config.LogitBias} {
+		for token, value := range bias {
+			if value < MinLogitBias || value > MaxLogitBias {
+				return nil, fmt.Errorf("invalid logit bias %d for token %d: must be between %d and %d",
+					value, token, MinLogitBias, MaxLogitBias)
+			}
+			merged[strconv.Itoa(token)] = int64(value)
+		}
+	}
+	return merged, nil
+}
+
+// resolveSetting returns the model setting in effect: the config's value
+// wins over the agent's unless config.PreferAgentSettings is set.
+func resolveSetting[T any](config *RunConfig, configValue, agentValue *T) *T {
+	if config.PreferAgentSettings {
+		return firstNonNil(agentValue, configValue)
+	}
+	return firstNonNil(configValue, agentValue)
+}
+
+// resolveStop picks the stop sequences with the same precedence as
+// resolveSetting, treating an empty slice as unset.
+func resolveStop(agent *Agent, config *RunConfig) []string {
+	first, second := config.Stop, agent.Stop
+	if config.PreferAgentSettings {
+		first, second = second, first
+	}
+	if len(first) > 0 {
+		return first
+	}
+	return second
+}
+
+// firstNonNil returns the first of the given settings that is set.
+func firstNonNil[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// resolveResponseFormat returns the response format in effect for the agent.
+// After a handoff it must be called with the new agent so each turn, and the
+// final output, follow the schema of the agent that produced it.
+func resolveResponseFormat(agent *Agent, config *RunConfig) *jsonschema.ResponseFormat {
+	if config.ResponseFormat != nil {
+		return config.ResponseFormat
+	}
+	return agent.ResponseFormat
+}
+
+// predictionModels lists model prefixes that accept predicted outputs.
+var predictionModels = []string{"gpt-4o", "gpt-4.1"}
+
+// supportsPrediction reports whether the model accepts predicted outputs.
+func supportsPrediction(model string) bool {
+	for _, prefix := range predictionModels {
+		if strings.HasPrefix(model, prefix) && !strings.Contains(model, "audio") && !strings.Contains(model, "realtime") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSystemMessage reports whether the history already contains a system or
+// developer message supplied by the caller.
+func hasSystemMessage(history []openai.ChatCompletionMessageParamUnion) bool {
+	for _, msg := range history {
+		if msg.OfSystem != nil || msg.OfDeveloper != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Runner) handleToolCalls(
+	ctx context.Context,
 	toolCalls []openai.ChatCompletionMessageToolCall,
 	toolMap map[string]Tool,
+	cache toolResultCache,
 	contextParams ContextVariables,
 	currentAgent *Agent,
-) ([]openai.ChatCompletionMessageParamUnion, []ToolCall, *Agent) {
+	config *RunConfig,
+	summarize completionFunc,
+) ([]openai.ChatCompletionMessageParamUnion, []ToolCall, *Handoff, Usage) {
 	var messages []openai.ChatCompletionMessageParamUnion
+	var summaryUsage Usage
+	var extraMessages []openai.ChatCompletionMessageParamUnion
 	var recordedToolCalls []ToolCall
-	nextAgent := currentAgent
+	var blocks []openai.ChatCompletionContentPartTextParam
+	var firstID string
+	var otherIDs []string
+	var handoff *Handoff
+
+	outcomes := r.executeToolCalls(ctx, toolCalls, toolMap, cache, contextParams, currentAgent, config)
+
+	for i, toolCall := range toolCalls {
+		toolName := toolCall.Function.Name
+		args := toolCall.Function.Arguments
+		outcome := outcomes[i]
+		tool, result, err, attempts := outcome.tool, outcome.result, outcome.err, outcome.attempts
 
-	for _, toolCall := range toolCalls {
+		// Unwrap structured results carrying extra messages
+		if tr, ok := asToolResult(result); ok {
+			result = tr.Output
+			extraMessages = append(extraMessages, tr.Messages...)
+		}
+
+		// Record tool call
+		recorded := ToolCall{
+			ID:        truncateToolCallID(toolCall.ID),
+			ToolName:  toolName,
+			Arguments: args,
+			Result:    result,
+			Error:     err,
+			Duration:  outcome.duration,
+			Attempts:  attempts,
+			Cached:    outcome.cached,
+		}
+		recordedToolCalls = append(recordedToolCalls, recorded)
+		toolEnd := map[string]any{"tool": toolName, "duration_ms": recorded.Duration.Milliseconds(), "attempts": attempts}
+		if err != nil {
+			toolEnd["error"] = err.Error()
+		}
+		if outcome.cached {
+			toolEnd["cached"] = true
+		}
+		eventsFromContext(ctx).emit(EventToolEnd, currentAgent, toolEnd)
+
+		// Check for Handoff
+		if h, ok := asHandoff(result); ok {
+			handoff = h
+			recorded.Result = fmt.Sprintf("Transferred to %s", h.Agent.Name)
+		} else if err == nil {
+			// Only the model sees the transformed result; the step keeps the original
+			if tool.ResultTransform != nil {
+				recorded.Result = tool.ResultTransform(result)
+			}
+			if tool.SummarizeResult && config.Summarizer != nil {
+				summary, u, sErr := r.summarizeToolResult(ctx, summarize, config, recorded)
+				summaryUsage.Add(u)
+				if sErr != nil {
+					LoggerFromContext(ctx).Warn("failed to summarize tool result", "tool", toolName, "error", sErr)
+				} else {
+					recorded.Result = summary
+				}
+			}
+		}
+
+		// Add tool output to history
+		toolCallID := truncateToolCallID(toolCall.ID)
+		resultStr, fmtErr := formatToolResult(config, recorded)
+		if fmtErr != nil {
+			LoggerFromContext(ctx).Warn("failed to format tool result", "tool", toolName, "error", fmtErr)
+			resultStr = fmt.Sprintf("Error: tool %s returned a result that could not be formatted", toolName)
+			recordedToolCalls[len(recordedToolCalls)-1].Error = NewToolExecutionError(toolName, fmtErr)
+		}
+		if config.ConsolidateToolResults {
+			blocks = append(blocks, openai.ChatCompletionContentPartTextParam{
+				Text: fmt.Sprintf("[%s] %s: %s", toolCallID, toolName, resultStr),
+			})
+			if firstID == "" {
+				firstID = toolCallID
+			} else {
+				otherIDs = append(otherIDs, toolCallID)
+			}
+			continue
+		}
+		messages = append(messages, openai.ToolMessage(resultStr, toolCallID))
+	}
+
+	// A consolidated batch is answered under the first call's ID; each block
+	// is labelled with the ID of the call it belongs to. The API rejects
+	// histories with unanswered call IDs, so the other calls get a stub
+	// pointing at the consolidated result
+	if len(blocks) > 0 {
+		messages = append(messages, openai.ToolMessage(blocks, firstID))
+		for _, id := range otherIDs {
+			messages = append(messages, openai.ToolMessage("See consolidated result in "+firstID, id))
+		}
+	}
+
+	// Extra messages go after all tool messages so every tool call is answered first
+	messages = append(messages, extraMessages...)
+
+	return messages, recordedToolCalls, handoff, summaryUsage
+}
+
+// toolOutcome is the result of executing one tool call.
+type toolOutcome struct {
+	tool     Tool
+	result   any
+	err      error
+	attempts int
+	duration time.Duration
+	cached   bool
+}
+
+// toolResultCache holds the results of cacheable tools for one run, keyed
+// by toolCacheKey.
+type toolResultCache map[string]any
+
+// toolCacheKey identifies a call by tool name and arguments. Arguments are
+// re-encoded so that whitespace and key order do not matter.
+func toolCacheKey(name, args string) string {
+	var v any
+	if err := json.Unmarshal([]byte(args), &v); err == nil {
+		if data, err := json.Marshal(v); err == nil {
+			args = string(data)
+		}
+	}
+	return name + "\x00" + args
+}
+
+// executeToolCalls runs the callbacks of a batch of tool calls and returns
+// their outcomes in call order. Calls of cacheable tools answered from cache
+// are resolved first. Hooks and tool_start events run in call order; when
+// parallel tool calls are enabled the callbacks then run concurrently, each
+// on its own copy of the context variables, and the changes are merged back
+// in call order. Successful results of cacheable tools are added to cache.
+func (r *Runner) executeToolCalls(
+	ctx context.Context,
+	toolCalls []openai.ChatCompletionMessageToolCall,
+	toolMap map[string]Tool,
+	cache toolResultCache,
+	contextParams ContextVariables,
+	currentAgent *Agent,
+	config *RunConfig,
+) []toolOutcome {
+	outcomes := make([]toolOutcome, len(toolCalls))
+	var pending []int
+
+	for i, toolCall := range toolCalls {
 		toolStart := time.Now()
 		toolName := toolCall.Function.Name
 		args := toolCall.Function.Arguments
 
 		tool, found := toolMap[toolName]
-		var result any
-		var err error
-
+		outcomes[i].tool = tool
 		if !found {
 			// Provide helpful error with available tools
 			available := make([]string, 0, len(toolMap))
 			for name := range toolMap {
 				available = append(available, name)
 			}
-			result = fmt.Sprintf("Error: Tool %s not found. Available tools: %v", toolName, available)
-			err = fmt.Errorf("tool %s not found (available: %v)", toolName, available)
+			outcomes[i].result = fmt.Sprintf("Error: Tool %s not found. Available tools: %v", toolName, available)
+			outcomes[i].err = fmt.Errorf("tool %s not found (available: %v)", toolName, available)
+		} else if result, hit := cache[toolCacheKey(toolName, args)]; hit && tool.Cacheable {
+			outcomes[i].result, outcomes[i].cached = result, true
+		} else if startErr := runToolStartHook(ctx, currentAgent, toolName, args); startErr != nil {
+			outcomes[i].result = fmt.Sprintf("Error: tool %s was not run: %v", toolName, startErr)
+			outcomes[i].err = fmt.Errorf("%w: %w", ErrToolSkipped, startErr)
 		} else {
-			result, err = tool.Execute(args, contextParams)
-			if err != nil {
-				result = fmt.Sprintf("Error executing tool %s: %v", toolName, err)
-				err = NewToolExecutionError(toolName, err)
+			LoggerFromContext(ctx).Debug("executing tool", "tool", toolName)
+			eventsFromContext(ctx).emit(EventToolStart, currentAgent, map[string]any{"tool": toolName, "arguments": args})
+			pending = append(pending, i)
+		}
+		outcomes[i].duration = time.Since(toolStart)
+	}
+
+	run := func(i int, vars ContextVariables) {
+		toolStart := time.Now()
+		toolName := toolCalls[i].Function.Name
+		tool := outcomes[i].tool
+		result, attempts, err := executeTool(ctx, currentAgent, tool, toolCalls[i].Function.Arguments, vars)
+		if errors.Is(err, ErrToolTimeout) {
+			result = fmt.Sprintf("Error: tool %s timed out after %s", toolName, tool.Timeout)
+			err = NewToolExecutionError(toolName, err)
+		} else if err != nil {
+			result = fmt.Sprintf("Error executing tool %s: %v", toolName, err)
+			err = NewToolExecutionError(toolName, err)
+		}
+		outcomes[i].result, outcomes[i].attempts, outcomes[i].err = result, attempts, err
+		outcomes[i].duration += time.Since(toolStart)
+	}
+
+	if len(pending) < 2 || !parallelToolCalls(currentAgent, config) {
+		for _, i := range pending {
+			run(i, contextParams)
+		}
+	} else {
+		snapshot := maps.Clone(contextParams)
+		copies := make([]ContextVariables, len(pending))
+		var wg sync.WaitGroup
+		for n, i := range pending {
+			copies[n] = maps.Clone(snapshot)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				run(i, copies[n])
+			}()
+		}
+		wg.Wait()
+
+		for _, vars := range copies {
+			mergeContextVariables(contextParams, snapshot, vars)
+		}
+	}
+
+	for _, i := range pending {
+		if outcomes[i].tool.Cacheable && outcomes[i].err == nil {
+			cache[toolCacheKey(toolCalls[i].Function.Name, toolCalls[i].Function.Arguments)] = outcomes[i].result
+		}
+	}
+	return outcomes
+}
+
+// mergeContextVariables applies to vars the keys a tool set or deleted in
+// its copy of snapshot. When parallel calls change the same key, the call
+// merged last wins, except that notes appended to the scratchpad are all
+// kept in merge order.
+func mergeContextVariables(vars, snapshot, changed ContextVariables) {
+	for k, v := range changed {
+		if k == ScratchpadKey && mergeScratchpad(vars, snapshot, changed) {
+			continue
+		}
+		if old, ok := snapshot[k]; !ok || !reflect.DeepEqual(old, v) {
+			vars[k] = v
+		}
+	}
+	for k := range snapshot {
+		if _, ok := changed[k]; !ok {
+			delete(vars, k)
+		}
+	}
+}
+
+// resolveToolChoice translates the tool choice in effect into the request
+// parameter, checking that a named tool is among tools.
+func resolveToolChoice(agent *Agent, config *RunConfig, tools []openai.ChatCompletionToolParam) (openai.ChatCompletionToolChoiceOptionUnionParam, error) {
+	choice := config.ToolChoice
+	if choice == "" || config.PreferAgentSettings && agent.ToolChoice != "" {
+		choice = agent.ToolChoice
+	}
+
+	var param openai.ChatCompletionToolChoiceOptionUnionParam
+	switch choice {
+	case "", ToolChoiceAuto:
+		// The API defaults to auto; sending it without tools is an error
+		return param, nil
+	case ToolChoiceNone:
+		if len(tools) > 0 {
+			param.OfAuto = openai.String(choice)
+		}
+		return param, nil
+	case ToolChoiceRequired:
+		if len(tools) == 0 {
+			return param, fmt.Errorf("%w: %s needs tools but agent %s has none", ErrInvalidToolChoice, choice, agent.Name)
+		}
+		param.OfAuto = openai.String(choice)
+		return param, nil
+	}
+
+	available := make([]string, 0, len(tools))
+	for _, t := range tools {
+		if t.Function.Name == choice {
+			param.OfChatCompletionNamedToolChoice = &openai.ChatCompletionNamedToolChoiceParam{
+				Function: openai.ChatCompletionNamedToolChoiceFunctionParam{Name: choice},
 			}
+			return param, nil
 		}
+		available = append(available, t.Function.Name)
+	}
+	return param, fmt.Errorf("%w: agent %s has no tool %q (available: %v)", ErrInvalidToolChoice, agent.Name, choice, available)
+}
 
-		// Record tool call
-		recordedToolCalls = append(recordedToolCalls, ToolCall{
-			ToolName:  toolName,
-			Arguments: args,
-			Result:    result,
-			Error:     err,
-			Duration:  time.Since(toolStart),
-		})
+// forcesToolCall reports whether a tool choice makes the model call a tool.
+func forcesToolCall(choice openai.ChatCompletionToolChoiceOptionUnionParam) bool {
+	return choice.OfChatCompletionNamedToolChoice != nil || choice.OfAuto.Value == ToolChoiceRequired
+}
 
-		// Check for Handoff
-		if extractedAgent, ok := IsHandoff(result); ok {
-			nextAgent = extractedAgent
-			result = fmt.Sprintf("Transferred to %s", nextAgent.Name)
+// parallelToolCalls reports whether the agent may call tools in parallel,
+// honoring the RunConfig override.
+func parallelToolCalls(agent *Agent, config *RunConfig) bool {
+	if config.ParallelToolCalls != nil {
+		return *config.ParallelToolCalls
+	}
+	return agent.ParallelToolCalls
+}
+
+// runToolStartHook calls the agent's OnToolStart hook, if any.
+func runToolStartHook(ctx context.Context, agent *Agent, toolName, args string) error {
+	if agent.OnToolStart == nil {
+		return nil
+	}
+	return agent.OnToolStart(ctx, toolName, args)
+}
+
+// executeTool runs a tool call followed by the agent's OnToolEnd hook.
+func executeTool(
+	ctx context.Context,
+	agent *Agent,
+	tool Tool,
+	args string,
+	vars ContextVariables,
+) (any, int, error) {
+	result, attempts, err := tool.executeWithRetry(ctx, args, vars)
+
+	if agent.OnToolEnd != nil {
+		if hookErr := agent.OnToolEnd(ctx, tool.Name, result, err); hookErr != nil {
+			return nil, attempts, fmt.Errorf("OnToolEnd hook failed: %w", hookErr)
 		}
+	}
+	return result, attempts, err
+}
 
-		// Add tool output to history
-		toolCallID := toolCall.ID
-		if len(toolCallID) > 40 {
-			toolCallID = toolCallID[:40]
+// summarizeToolResult condenses a tool result with a single call to the
+// configured Summarizer agent.
+func (r *Runner) summarizeToolResult(
+	ctx context.Context,
+	complete completionFunc,
+	config *RunConfig,
+	call ToolCall,
+) (string, Usage, error) {
+	content, err := formatToolResult(config, call)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage(fmt.Sprintf("Summarize the following output of the %s tool:\n\n%s",
+			call.ToolName, content)),
+	}
+	req, err := r.prepareRequest(ctx, config.Summarizer, &RunConfig{}, nil, history)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	completion, err := complete(ctx, req)
+	if err != nil {
+		return "", Usage{}, err
+	}
+	if len(completion.Choices) == 0 {
+		return "", completionUsage(completion), ErrNoChoices
+	}
+	return completion.Choices[0].Message.Content, completionUsage(completion), nil
+}
+
+// maxToolCallIDLength is the longest tool call ID the API accepts.
+const maxToolCallIDLength = 40
+
+// truncateToolCallID shortens tool call IDs the API would reject.
+func truncateToolCallID(id string) string {
+	if len(id) > maxToolCallIDLength {
+		return id[:maxToolCallIDLength]
+	}
+	return id
+}
+
+// truncateToolCallIDs shortens in place the IDs of calls the API would reject.
+func truncateToolCallIDs(calls []openai.ChatCompletionMessageToolCall) {
+	for i := range calls {
+		calls[i].ID = truncateToolCallID(calls[i].ID)
+	}
+}
+
+// completionUsage converts the usage reported for a completion.
+func completionUsage(completion *openai.ChatCompletion) Usage {
+	return Usage{
+		PromptTokens:             int(completion.Usage.PromptTokens),
+		CompletionTokens:         int(completion.Usage.CompletionTokens),
+		TotalTokens:              int(completion.Usage.TotalTokens),
+		AcceptedPredictionTokens: int(completion.Usage.CompletionTokensDetails.AcceptedPredictionTokens),
+		RejectedPredictionTokens: int(completion.Usage.CompletionTokensDetails.RejectedPredictionTokens),
+	}
+}
+
+// finalOutput returns the content of the final assistant message, or its
+// refusal when the model declined to answer.
+func finalOutput(message openai.ChatCompletionMessage) string {
+	if message.Content != "" {
+		return message.Content
+	}
+	return message.Refusal
+}
+
+// formatToolResult renders a tool call's result as the tool message content,
+// using the configured ToolResultFormatter when set. A formatter that panics,
+// e.g. on a result it cannot serialize, yields ErrUnformattableResult
+// instead of crashing the run.
+func formatToolResult(config *RunConfig, call ToolCall) (content string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %T: %v", ErrUnformattableResult, call.Result, p)
 		}
-		resultStr := fmt.Sprintf("%v", result)
-		messages = append(messages, openai.ToolMessage(resultStr, toolCallID))
+	}()
+
+	if config.ToolResultFormatter != nil {
+		return config.ToolResultFormatter(call), nil
 	}
+	return toolResultContent(call.Result), nil
+}
 
-	return messages, recordedToolCalls, nextAgent
+// toolResultContent renders a result for the model: strings as they are,
+// anything else as JSON so maps, slices and structs stay parseable. Values
+// that cannot be marshaled fall back to their %v form.
+func toolResultContent(result any) string {
+	switch v := result.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	if data, err := json.Marshal(result); err == nil {
+		return string(data)
+	}
+	return fmt.Sprintf("%v", result)
 }
@@ -2,11 +2,19 @@ package agents
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
 func TestNewRunner(t *testing.T) {
@@ -20,6 +28,150 @@ func TestNewRunner(t *testing.T) {
 	if runner.Client != client {
 		t.Error("expected runner to store the provided client")
 	}
+	if runner.Chat != &client.Chat.Completions {
+		t.Error("expected runner to use the client's chat completions")
+	}
+}
+
+func TestRunner_NoClient(t *testing.T) {
+	runner := NewRunnerWithClient(&fakeChat{})
+	ctx := context.Background()
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+	if err := runner.Ping(ctx); !errors.Is(err, ErrNoClient) {
+		t.Errorf("Ping: expected ErrNoClient, got %v", err)
+	}
+	if _, err := runner.UploadFile(ctx, "a.txt", strings.NewReader("a")); !errors.Is(err, ErrNoClient) {
+		t.Errorf("UploadFile: expected ErrNoClient, got %v", err)
+	}
+	if err := runner.DeleteFile(ctx, "file_1"); !errors.Is(err, ErrNoClient) {
+		t.Errorf("DeleteFile: expected ErrNoClient, got %v", err)
+	}
+	var out strings.Builder
+	if _, err := runner.RunStreamTo(ctx, NewAgent("Assistant"), messages, nil, nil, &out); !errors.Is(err, ErrNoClient) {
+		t.Errorf("RunStreamTo: expected ErrNoClient, got %v", err)
+	}
+	if _, err := (&Runner{}).Run(ctx, NewAgent("Assistant"), messages, nil, nil); !errors.Is(err, ErrNoClient) {
+		t.Errorf("Run: expected ErrNoClient, got %v", err)
+	}
+}
+
+func TestNewRunnerWithClient_MultiTurnTools(t *testing.T) {
+	chat := &fakeChat{responses: []string{
+		toolCallCompletion(mockToolCall{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}),
+		toolCallCompletion(mockToolCall{ID: "call_2", Name: "get_weather", Arguments: `{"city":"Rome"}`}),
+		textCompletion("Sunny in Paris, rainy in Rome."),
+	}}
+	runner := NewRunnerWithClient(chat)
+
+	weather := map[string]string{"Paris": "sunny", "Rome": "rainy"}
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionTool("get_weather", "Get the weather", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+			return weather[args["city"].(string)], nil
+		}),
+	}
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Weather in Paris and Rome?")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.FinalOutput != "Sunny in Paris, rainy in Rome." {
+		t.Errorf("unexpected output %q", result.FinalOutput)
+	}
+	requests := chat.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+	// Each turn sends the tool results of the turns before it
+	var results []string
+	for _, msg := range requests[2].Messages {
+		if msg.OfTool != nil {
+			results = append(results, msg.OfTool.Content.OfString.Value)
+		}
+	}
+	if !reflect.DeepEqual(results, []string{"sunny", "rainy"}) {
+		t.Errorf("expected both tool results in the last request, got %v", results)
+	}
+}
+
+func TestNewRunnerWithClient_Handoff(t *testing.T) {
+	chat := &fakeChat{responses: []string{
+		toolCallCompletion(mockToolCall{Name: "transfer_to_billing", Arguments: `{}`}),
+		textCompletion("Your invoice is on its way."),
+	}}
+	runner := NewRunnerWithClient(chat)
+
+	billing := NewAgent("Billing")
+	billing.Instructions = "You handle billing."
+	triage := NewAgent("Triage")
+	triage.Handoffs = []*Handoff{NewHandoff(billing)}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Where is my invoice?")}
+	result, err := runner.Run(context.Background(), triage, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if got := result.Steps[len(result.Steps)-1].AgentName; got != "Billing" {
+		t.Errorf("expected billing to finish the run, got %s", got)
+	}
+	requests := chat.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	system := requests[1].Messages[0].OfSystem
+	if system == nil || system.Content.OfString.Value != "You handle billing." {
+		t.Errorf("expected billing's instructions after the handoff, got %+v", requests[1].Messages[0])
+	}
+}
+
+func TestNewRunnerWithClient_MaxTurns(t *testing.T) {
+	echo := mockToolCall{Name: "echo", Arguments: `{}`}
+	chat := &fakeChat{responses: []string{toolCallCompletion(echo), toolCallCompletion(echo)}}
+	runner := NewRunnerWithClient(chat)
+
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionTool("echo", "Echo", nil, func(map[string]any, ContextVariables) (any, error) {
+			return "again", nil
+		}),
+	}
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("loop")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, &RunConfig{MaxTurns: 2})
+
+	if !errors.Is(err, ErrMaxTurnsExceeded) {
+		t.Fatalf("expected ErrMaxTurnsExceeded, got %v", err)
+	}
+	if result == nil || result.StopReason != StopReasonMaxTurns {
+		t.Fatalf("expected partial result with StopReason=%s, got %+v", StopReasonMaxTurns, result)
+	}
+	if got := len(chat.Requests()); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestPing(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		runner, mock := newMockRunner(t, `{"object":"list","data":[]}`)
+		if err := runner.Ping(context.Background()); err != nil {
+			t.Fatalf("Ping failed: %v", err)
+		}
+		if paths := mock.Paths(); len(paths) != 1 || paths[0] != "GET /models" {
+			t.Errorf("expected a single GET /models, got %v", paths)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		// No scripted response, so the mock answers with a 500
+		runner, _ := newMockRunner(t)
+		err := runner.Ping(context.Background())
+		var apiErr *openai.Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected wrapped *openai.Error, got %v", err)
+		}
+	})
 }
 
 func TestRunNoMessages(t *testing.T) {
@@ -40,14 +192,200 @@ func TestRunNoMessages(t *testing.T) {
 }
 
 func TestRunMaxTurnsExceeded(t *testing.T) {
-	// This test would require mocking the OpenAI client to return responses
-	// For now, we'll test the max turns configuration
-	config := &RunConfig{
-		MaxTurns: 1,
+	echo := mockToolCall{Name: "echo", Arguments: `{}`}
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(echo),
+		toolCallCompletion(echo),
+	)
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("echo", "Echo", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return "again", nil
+		}),
 	}
 
-	if config.MaxTurns != 1 {
-		t.Errorf("expected MaxTurns=1, got %d", config.MaxTurns)
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("loop")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, &RunConfig{MaxTurns: 2})
+
+	if !errors.Is(err, ErrMaxTurnsExceeded) {
+		t.Fatalf("expected ErrMaxTurnsExceeded, got %v", err)
+	}
+	if result == nil {
+		t.Fatal("expected partial result")
+	}
+	if result.StopReason != StopReasonMaxTurns {
+		t.Errorf("expected StopReason=%s, got %s", StopReasonMaxTurns, result.StopReason)
+	}
+	if len(result.Steps) != 2 {
+		t.Errorf("expected 2 steps, got %d", len(result.Steps))
+	}
+
+	for _, step := range result.Steps {
+		if step.Usage.TotalTokens != 15 {
+			t.Errorf("expected step %d to record 15 tokens, got %d", step.StepNumber, step.Usage.TotalTokens)
+		}
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("expected run total of 30 tokens, got %d", result.Usage.TotalTokens)
+	}
+}
+
+func TestRunTokenBudgetExceeded(t *testing.T) {
+	echo := mockToolCall{Name: "echo", Arguments: `{}`}
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(echo),
+		toolCallCompletion(echo),
+		textCompletion("never reached"),
+	)
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("echo", "Echo", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return "again", nil
+		}),
+	}
+
+	config := DefaultRunConfig()
+	config.MaxTotalTokens = 20 // each mock turn uses 15 tokens
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("loop")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+
+	if !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Fatalf("expected ErrTokenBudgetExceeded, got %v", err)
+	}
+	if result == nil || result.StopReason != StopReasonTokenBudget {
+		t.Fatalf("expected partial result with StopReason=%s, got %+v", StopReasonTokenBudget, result)
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("expected final usage of 30 tokens, got %d", result.Usage.TotalTokens)
+	}
+	if n := len(mock.Requests()); n != 2 {
+		t.Errorf("expected 2 LLM calls, got %d", n)
+	}
+}
+
+func TestRunMaxToolCallsExceeded(t *testing.T) {
+	echo := mockToolCall{Name: "echo", Arguments: `{}`}
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(echo, echo),
+		toolCallCompletion(echo, echo),
+		textCompletion("never reached"),
+	)
+
+	calls := 0
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("echo", "Echo", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			calls++
+			return "again", nil
+		}),
+	}
+	agent.ParallelToolCalls = false
+
+	config := DefaultRunConfig()
+	config.MaxToolCalls = 3
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("loop")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+
+	if !errors.Is(err, ErrMaxToolCallsExceeded) {
+		t.Fatalf("expected ErrMaxToolCallsExceeded, got %v", err)
+	}
+	if result == nil || result.StopReason != StopReasonMaxToolCalls {
+		t.Fatalf("expected partial result with StopReason=%s, got %+v", StopReasonMaxToolCalls, result)
+	}
+	if calls != 2 || result.ToolCallCount != 2 {
+		t.Errorf("expected only the first turn's 2 calls to run, got %d (count %d)", calls, result.ToolCallCount)
+	}
+	if len(result.Steps) != 2 {
+		t.Errorf("expected 2 steps, got %d", len(result.Steps))
+	}
+	if n := len(mock.Requests()); n != 2 {
+		t.Errorf("expected 2 LLM calls, got %d", n)
+	}
+}
+
+func TestRunUnexpectedToolCall(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "hallucinated", Arguments: `{}`}),
+	)
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	result, err := runner.Run(context.Background(), NewAgent("NoTools"), messages, nil, nil)
+
+	if !errors.Is(err, ErrUnexpectedToolCall) {
+		t.Fatalf("expected ErrUnexpectedToolCall, got %v", err)
+	}
+	if result == nil || result.StopReason != StopReasonUnexpectedToolCall {
+		t.Fatalf("expected partial result with StopReason=%s, got %+v", StopReasonUnexpectedToolCall, result)
+	}
+	if n := len(mock.Requests()); n != 1 {
+		t.Errorf("expected a single LLM call, got %d", n)
+	}
+}
+
+func TestRun_ConcurrentSharedRunner(t *testing.T) {
+	const runs = 20
+
+	responses := make([]string, 0, runs*2)
+	for range runs {
+		responses = append(responses,
+			toolCallCompletion(mockToolCall{Name: "count", Arguments: `{}`}),
+			textCompletion("done"),
+		)
+	}
+	runner, _ := newMockRunner(t, responses...)
+
+	agent := NewAgent("Shared")
+	agent.Tools = []Tool{
+		FunctionTool("count", "Count calls", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+			n, _ := vars["calls"].(int)
+			vars["calls"] = n + 1
+			return "ok", nil
+		}),
+	}
+	config := DefaultRunConfig()
+	config.ContextVariables = ContextVariables{"tenant": "shared"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+			if _, err := runner.Run(context.Background(), agent, messages, nil, config); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent Run failed: %v", err)
+	}
+	if len(config.ContextVariables) != 1 {
+		t.Errorf("expected seed variables to be left untouched, got %v", config.ContextVariables)
+	}
+}
+
+func TestRunStopReasonCompleted(t *testing.T) {
+	runner, _ := newMockRunner(t, textCompletion("hello"))
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	result, err := runner.Run(context.Background(), NewAgent("TestAgent"), messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.StopReason != StopReasonCompleted {
+		t.Errorf("expected StopReason=%s, got %s", StopReasonCompleted, result.StopReason)
+	}
+	if result.FinalOutput != "hello" {
+		t.Errorf("expected FinalOutput=hello, got %q", result.FinalOutput)
 	}
 }
 
@@ -64,12 +402,16 @@ func TestRunTimeout(t *testing.T) {
 		openai.UserMessage("test"),
 	}
 
-	_, err := runner.Run(ctx, agent, messages, nil, nil)
+	result, err := runner.Run(ctx, agent, messages, nil, nil)
 
 	// The error should be related to context cancellation
 	if err == nil {
 		t.Error("expected error for cancelled context")
 	}
+
+	if result == nil || result.StopReason != StopReasonCancelled {
+		t.Errorf("expected partial result with StopReason=%s, got %+v", StopReasonCancelled, result)
+	}
 }
 
 func TestRunWithTimeout(t *testing.T) {
@@ -171,6 +513,1597 @@ func TestContextVariablesInitialization(t *testing.T) {
 	}
 }
 
+func TestPrepareRequest_SystemMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		instructions any
+		config       *RunConfig
+		history      []openai.ChatCompletionMessageParamUnion
+		wantSystem   bool
+		wantMessages int
+	}{
+		{
+			name:         "instructions injected",
+			instructions: "Be brief.",
+			config:       &RunConfig{},
+			history:      []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+			wantSystem:   true,
+			wantMessages: 2,
+		},
+		{
+			name:         "empty instructions skipped",
+			instructions: "   ",
+			config:       &RunConfig{},
+			history:      []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+			wantSystem:   false,
+			wantMessages: 1,
+		},
+		{
+			name:         "suppressed by config",
+			instructions: "Be brief.",
+			config:       &RunConfig{SuppressSystemMessage: true},
+			history:      []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+			wantSystem:   false,
+			wantMessages: 1,
+		},
+		{
+			name:         "caller supplied system message",
+			instructions: "Be brief.",
+			config:       &RunConfig{},
+			history: []openai.ChatCompletionMessageParamUnion{
+				openai.SystemMessage("Custom system prompt"),
+				openai.UserMessage("hi"),
+			},
+			wantSystem:   true,
+			wantMessages: 2,
+		},
+		{
+			name:         "caller supplied developer message",
+			instructions: "Be brief.",
+			config:       &RunConfig{},
+			history: []openai.ChatCompletionMessageParamUnion{
+				openai.DeveloperMessage("Custom developer prompt"),
+				openai.UserMessage("hi"),
+			},
+			wantSystem:   false,
+			wantMessages: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.Instructions = tt.instructions
+
+			req, err := runner.prepareRequest(context.Background(), agent, tt.config, nil, tt.history)
+			if err != nil {
+				t.Fatalf("prepareRequest failed: %v", err)
+			}
+
+			if len(req.Messages) != tt.wantMessages {
+				t.Fatalf("expected %d messages, got %d", tt.wantMessages, len(req.Messages))
+			}
+
+			if got := req.Messages[0].OfSystem != nil; got != tt.wantSystem {
+				t.Errorf("expected first message system=%v, got %v", tt.wantSystem, got)
+			}
+		})
+	}
+}
+
+func TestRun_MixedRoleHistory(t *testing.T) {
+	runner, mock := newMockRunner(t, textCompletion("model reply"))
+
+	// A few-shot exchange ending in an assistant message must be passed
+	// through as history and not be mistaken for the final output.
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.DeveloperMessage("Answer in one word."),
+		openai.UserMessage("Capital of France?"),
+		openai.AssistantMessage("Paris"),
+		openai.UserMessage("Capital of Italy?"),
+		openai.AssistantMessage("Rome"),
+	}
+
+	result, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.FinalOutput != "model reply" {
+		t.Errorf("expected final output from the model, got %q", result.FinalOutput)
+	}
+
+	sent := mock.Requests()[0]["messages"].([]any)
+	wantRoles := []string{"developer", "user", "assistant", "user", "assistant"}
+	if len(sent) != len(wantRoles) {
+		t.Fatalf("expected %d messages sent, got %d", len(wantRoles), len(sent))
+	}
+	for i, role := range wantRoles {
+		if got := sent[i].(map[string]any)["role"]; got != role {
+			t.Errorf("message %d: expected role %s, got %v", i, role, got)
+		}
+	}
+}
+
+func TestRun_TruncatedOutput(t *testing.T) {
+	runner, _ := newMockRunner(t, completionJSON(map[string]any{
+		"role":    "assistant",
+		"content": `{"answer":"par`,
+	}, "length"))
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	result, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Truncated {
+		t.Error("expected Truncated=true for finish_reason length")
+	}
+}
+
+func TestRun_Refusal(t *testing.T) {
+	errRefused := errors.New("refused")
+	tests := []struct {
+		name       string
+		config     *RunConfig
+		wantOutput string
+		wantErr    error
+	}{
+		{
+			name:       "raw refusal",
+			config:     &RunConfig{},
+			wantOutput: "I can't help with that.",
+		},
+		{
+			name:       "fallback",
+			config:     &RunConfig{RefusalFallback: "Sorry, I can't answer that."},
+			wantOutput: "Sorry, I can't answer that.",
+		},
+		{
+			name: "hook error",
+			config: &RunConfig{
+				RefusalFallback: "Sorry, I can't answer that.",
+				OnRefusal: func(_ context.Context, agent *Agent, refusal string) error {
+					return fmt.Errorf("%s: %s: %w", agent.Name, refusal, errRefused)
+				},
+			},
+			wantOutput: "Sorry, I can't answer that.",
+			wantErr:    errRefused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, _ := newMockRunner(t, refusalCompletion("I can't help with that."))
+
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("something forbidden")}
+			result, err := runner.Run(context.Background(), NewAgent("TestAgent"), messages, nil, tt.config)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if result.FinalOutput != tt.wantOutput {
+				t.Errorf("expected FinalOutput %q, got %q", tt.wantOutput, result.FinalOutput)
+			}
+			if result.Refusal != "I can't help with that." {
+				t.Errorf("expected raw refusal, got %q", result.Refusal)
+			}
+		})
+	}
+}
+
+func TestRun_StoreResponses(t *testing.T) {
+	runner, mock := newMockRunner(t, textCompletion("stored"))
+
+	config := DefaultRunConfig()
+	config.StoreResponses = true
+	config.Metadata = map[string]string{"feature": "support"}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+	result, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	req := mock.Requests()[0]
+	if req["store"] != true {
+		t.Errorf("expected store=true, got %v", req["store"])
+	}
+	if md, _ := req["metadata"].(map[string]any); md["feature"] != "support" {
+		t.Errorf("expected metadata to be sent, got %v", req["metadata"])
+	}
+	if result.ResponseID != "chatcmpl-test" || result.Steps[0].ResponseID != "chatcmpl-test" {
+		t.Errorf("expected response ID on result and step, got %q and %q", result.ResponseID, result.Steps[0].ResponseID)
+	}
+}
+
+func TestRun_EndUserID(t *testing.T) {
+	runner, mock := newMockRunner(t, textCompletion("hi"), textCompletion("hi"))
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+	for _, id := range []string{"user-123", ""} {
+		if _, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, &RunConfig{EndUserID: id}); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	}
+
+	reqs := mock.Requests()
+	if reqs[0]["user"] != "user-123" {
+		t.Errorf("expected user to be sent, got %v", reqs[0]["user"])
+	}
+	if _, ok := reqs[1]["user"]; ok {
+		t.Errorf("expected no user field, got %v", reqs[1]["user"])
+	}
+}
+
+func TestRun_RunIDPropagatesToTools(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "whoami", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	var toolRunID, hookRunID string
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionToolWithContext("whoami", "Returns the run ID", nil,
+			func(ctx context.Context, _ map[string]any, _ ContextVariables) (any, error) {
+				toolRunID = RunIDFromContext(ctx)
+				return toolRunID, nil
+			}),
+	}
+	agent.OnBeforeRun = func(ctx context.Context, _ *Agent) error {
+		hookRunID = RunIDFromContext(ctx)
+		return nil
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("who am i")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.RunID == "" {
+		t.Fatal("expected result to carry a run ID")
+	}
+	if toolRunID != result.RunID {
+		t.Errorf("expected tool to see run ID %q, got %q", result.RunID, toolRunID)
+	}
+	if hookRunID != result.RunID {
+		t.Errorf("expected hook to see run ID %q, got %q", result.RunID, hookRunID)
+	}
+}
+
+func TestRun_DeadlinePropagatesToToolsAndGuardrails(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "budget", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	var toolDeadline, guardrailDeadline time.Time
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionToolWithContext("budget", "Reports the remaining budget", nil,
+			func(ctx context.Context, _ map[string]any, _ ContextVariables) (any, error) {
+				toolDeadline, _ = ctx.Deadline()
+				return "ok", nil
+			}),
+	}
+	agent.ConversationGuardrails = []ConversationGuardrail{{
+		Name: "deadline",
+		Func: func(ctx context.Context, _ []openai.ChatCompletionMessageParamUnion) error {
+			guardrailDeadline, _ = ctx.Deadline()
+			return nil
+		},
+	}}
+
+	start := time.Now()
+	config := &RunConfig{MaxTurns: 5, Timeout: time.Minute}
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	if _, err := runner.Run(context.Background(), agent, messages, nil, config); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for name, deadline := range map[string]time.Time{"tool": toolDeadline, "guardrail": guardrailDeadline} {
+		if deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+			t.Errorf("expected %s to see the run deadline, got %v", name, deadline)
+		}
+	}
+}
+
+func TestRun_APIKeyPerRun(t *testing.T) {
+	const runs = 8
+	responses := make([]string, runs)
+	for i := range responses {
+		responses[i] = textCompletion("ok")
+	}
+	runner, mock := newMockRunner(t, responses...)
+
+	var wg sync.WaitGroup
+	for i := range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			config := &RunConfig{MaxTurns: 1, APIKey: fmt.Sprintf("tenant-%d", i%2)}
+			if i%4 == 3 {
+				config.APIKey = ""
+			}
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+			if _, err := runner.Run(context.Background(), NewAgent("Assistant"), messages, nil, config); err != nil {
+				t.Errorf("Run failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	for _, h := range mock.Headers() {
+		counts[h.Get("Authorization")]++
+	}
+	want := map[string]int{"Bearer tenant-0": 4, "Bearer tenant-1": 2, "Bearer test-key": 2}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("unexpected keys used: %v", counts)
+	}
+}
+
+func TestPrepareRequest_PredictedOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		tools    []openai.ChatCompletionToolParam
+		wantSent bool
+	}{
+		{name: "supported model", model: "gpt-4o", wantSent: true},
+		{name: "supported mini model", model: "gpt-4.1-mini", wantSent: true},
+		{name: "unsupported model", model: "o3-mini", wantSent: false},
+		{
+			name:     "tools disable prediction",
+			model:    "gpt-4o",
+			tools:    []openai.ChatCompletionToolParam{{Function: openai.FunctionDefinitionParam{Name: "t"}}},
+			wantSent: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.Model = tt.model
+			config := &RunConfig{PredictedOutput: "func main() {}"}
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("rename main")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, config, tt.tools, history)
+			if err != nil {
+				t.Fatalf("prepareRequest failed: %v", err)
+			}
+
+			got := req.Prediction.Content.OfString.Valid()
+			if got != tt.wantSent {
+				t.Errorf("expected prediction sent=%v, got %v", tt.wantSent, got)
+			}
+		})
+	}
+}
+
+func TestPrepareRequest_LogitBias(t *testing.T) {
+	tests := []struct {
+		name    string
+		agent   map[int]int
+		config  map[int]int
+		want    map[string]int64
+		wantErr bool
+	}{
+		{name: "unset", want: nil},
+		{name: "agent only", agent: map[int]int{9642: 50}, want: map[string]int64{"9642": 50}},
+		{
+			name:   "config wins per token",
+			agent:  map[int]int{9642: 50, 2201: 10},
+			config: map[int]int{2201: -100},
+			want:   map[string]int64{"9642": 50, "2201": -100},
+		},
+		{name: "out of range", config: map[int]int{1: 101}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.LogitBias = tt.agent
+			config := &RunConfig{LogitBias: tt.config}
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("yes or no?")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, config, nil, history)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(req.LogitBias) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, req.LogitBias)
+			}
+			for k, v := range tt.want {
+				if req.LogitBias[k] != v {
+					t.Errorf("token %s: expected %d, got %d", k, v, req.LogitBias[k])
+				}
+			}
+		})
+	}
+}
+
+func TestPrepareRequest_PreferAgentSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		agentTemp   *float64
+		configTemp  *float64
+		agentMax    *int
+		configMax   *int
+		preferAgent bool
+		wantTemp    float64
+		wantMax     int64
+	}{
+		{
+			name:       "config wins by default",
+			agentTemp:  floatPtr(0.2),
+			configTemp: floatPtr(0.9),
+			agentMax:   intPtr(100),
+			configMax:  intPtr(500),
+			wantTemp:   0.9,
+			wantMax:    500,
+		},
+		{
+			name:        "agent wins when preferred",
+			agentTemp:   floatPtr(0.2),
+			configTemp:  floatPtr(0.9),
+			agentMax:    intPtr(100),
+			configMax:   intPtr(500),
+			preferAgent: true,
+			wantTemp:    0.2,
+			wantMax:     100,
+		},
+		{
+			name:        "config is fallback when preferred",
+			configTemp:  floatPtr(0.9),
+			configMax:   intPtr(500),
+			preferAgent: true,
+			wantTemp:    0.9,
+			wantMax:     500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.Temperature = tt.agentTemp
+			agent.MaxTokens = tt.agentMax
+			config := &RunConfig{
+				Temperature:         tt.configTemp,
+				MaxTokens:           tt.configMax,
+				PreferAgentSettings: tt.preferAgent,
+			}
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, config, nil, history)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := req.Temperature.Value; got != tt.wantTemp {
+				t.Errorf("expected temperature %v, got %v", tt.wantTemp, got)
+			}
+			if got := req.MaxTokens.Value; got != tt.wantMax {
+				t.Errorf("expected max tokens %d, got %d", tt.wantMax, got)
+			}
+		})
+	}
+}
+
+func TestPrepareRequest_SamplingSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		agent  func(*Agent)
+		config *RunConfig
+		want   map[string]any
+	}{
+		{
+			name:   "unset",
+			agent:  func(*Agent) {},
+			config: &RunConfig{},
+			want:   map[string]any{},
+		},
+		{
+			name: "agent values",
+			agent: func(a *Agent) {
+				a.TopP = floatPtr(0.5)
+				a.FrequencyPenalty = floatPtr(0.1)
+				a.PresencePenalty = floatPtr(0.2)
+				a.Seed = int64Ptr(7)
+			},
+			config: &RunConfig{},
+			want:   map[string]any{"top_p": 0.5, "frequency_penalty": 0.1, "presence_penalty": 0.2, "seed": 7.0},
+		},
+		{
+			name: "config wins",
+			agent: func(a *Agent) {
+				a.TopP = floatPtr(0.5)
+				a.Seed = int64Ptr(7)
+			},
+			config: &RunConfig{TopP: floatPtr(0.9), PresencePenalty: floatPtr(-1), Seed: int64Ptr(42)},
+			want:   map[string]any{"top_p": 0.9, "presence_penalty": -1.0, "seed": 42.0},
+		},
+		{
+			name: "agent wins when preferred",
+			agent: func(a *Agent) {
+				a.TopP = floatPtr(0.5)
+			},
+			config: &RunConfig{TopP: floatPtr(0.9), Seed: int64Ptr(42), PreferAgentSettings: true},
+			want:   map[string]any{"top_p": 0.5, "seed": 42.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			tt.agent(agent)
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, tt.config, nil, history)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, _ := json.Marshal(req)
+			var sent map[string]any
+			_ = json.Unmarshal(data, &sent)
+
+			got := make(map[string]any)
+			for _, key := range []string{"top_p", "frequency_penalty", "presence_penalty", "seed"} {
+				if v, ok := sent[key]; ok {
+					got[key] = v
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPrepareRequest_Stop(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentStop []string
+		config    *RunConfig
+		want      any
+	}{
+		{name: "unset", config: &RunConfig{}},
+		{name: "empty slice", agentStop: []string{}, config: &RunConfig{Stop: []string{}}},
+		{name: "agent", agentStop: []string{"END"}, config: &RunConfig{}, want: []any{"END"}},
+		{name: "config wins", agentStop: []string{"END"}, config: &RunConfig{Stop: []string{"---", "###"}}, want: []any{"---", "###"}},
+		{name: "agent wins when preferred", agentStop: []string{"END"}, config: &RunConfig{Stop: []string{"---"}, PreferAgentSettings: true}, want: []any{"END"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.Stop = tt.agentStop
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, tt.config, nil, history)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, _ := json.Marshal(req)
+			var sent map[string]any
+			_ = json.Unmarshal(data, &sent)
+
+			if got := sent["stop"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected stop %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPrepareRequest_ToolChoice(t *testing.T) {
+	lookup := Tool{Name: "lookup", Description: "Look up"}.ToParam()
+
+	tests := []struct {
+		name        string
+		agentChoice string
+		config      *RunConfig
+		tools       []openai.ChatCompletionToolParam
+		want        any
+		wantErr     bool
+	}{
+		{name: "unset", config: &RunConfig{}, tools: []openai.ChatCompletionToolParam{lookup}},
+		{name: "auto", config: &RunConfig{ToolChoice: ToolChoiceAuto}, tools: []openai.ChatCompletionToolParam{lookup}},
+		{name: "agent required", agentChoice: ToolChoiceRequired, config: &RunConfig{}, tools: []openai.ChatCompletionToolParam{lookup}, want: "required"},
+		{name: "config wins", agentChoice: ToolChoiceRequired, config: &RunConfig{ToolChoice: ToolChoiceNone}, tools: []openai.ChatCompletionToolParam{lookup}, want: "none"},
+		{name: "agent wins when preferred", agentChoice: ToolChoiceRequired, config: &RunConfig{ToolChoice: ToolChoiceNone, PreferAgentSettings: true}, tools: []openai.ChatCompletionToolParam{lookup}, want: "required"},
+		{
+			name:   "named tool",
+			config: &RunConfig{ToolChoice: "lookup"},
+			tools:  []openai.ChatCompletionToolParam{lookup},
+			want:   map[string]any{"type": "function", "function": map[string]any{"name": "lookup"}},
+		},
+		{name: "none without tools", config: &RunConfig{ToolChoice: ToolChoiceNone}},
+		{name: "unknown tool", config: &RunConfig{ToolChoice: "search"}, tools: []openai.ChatCompletionToolParam{lookup}, wantErr: true},
+		{name: "required without tools", config: &RunConfig{ToolChoice: ToolChoiceRequired}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.ToolChoice = tt.agentChoice
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, tt.config, tt.tools, history)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToolChoice) {
+					t.Fatalf("expected ErrInvalidToolChoice, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, _ := json.Marshal(req)
+			var sent map[string]any
+			_ = json.Unmarshal(data, &sent)
+
+			if got := sent["tool_choice"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected tool_choice %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRun_ToolChoiceAppliesUntilToolCalled(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "lookup", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	agent := NewAgent("Assistant")
+	agent.ToolChoice = "lookup"
+	agent.Tools = []Tool{
+		FunctionTool("lookup", "Look up", nil, func(map[string]any, ContextVariables) (any, error) {
+			return "found", nil
+		}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	if _, err := runner.Run(context.Background(), agent, messages, nil, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	requests := mock.Requests()
+	if _, ok := requests[0]["tool_choice"].(map[string]any); !ok {
+		t.Errorf("expected the first turn to force lookup, got %v", requests[0]["tool_choice"])
+	}
+	if choice, ok := requests[1]["tool_choice"]; ok {
+		t.Errorf("expected no forced tool after the call, got %v", choice)
+	}
+}
+
+func TestPrepareRequest_MaxHistoryMessages(t *testing.T) {
+	toolCall := openai.ChatCompletionMessage{
+		Role: "assistant",
+		ToolCalls: []openai.ChatCompletionMessageToolCall{
+			{ID: "call_1", Type: "function", Function: openai.ChatCompletionMessageToolCallFunction{Name: "a"}},
+			{ID: "call_2", Type: "function", Function: openai.ChatCompletionMessageToolCallFunction{Name: "b"}},
+		},
+	}.ToParam()
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("custom system"),
+		openai.UserMessage("first"),
+		openai.AssistantMessage("reply"),
+		openai.UserMessage("second"),
+		toolCall,
+		openai.ToolMessage("result a", "call_1"),
+		openai.ToolMessage("result b", "call_2"),
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{name: "unlimited", limit: 0, want: []string{"system", "user", "assistant", "user", "assistant", "tool", "tool"}},
+		{name: "window", limit: 4, want: []string{"system", "user", "assistant", "tool", "tool"}},
+		{name: "widened to keep tool pairing", limit: 1, want: []string{"system", "assistant", "tool", "tool"}},
+		{name: "larger than history", limit: 10, want: []string{"system", "user", "assistant", "user", "assistant", "tool", "tool"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			config := &RunConfig{MaxHistoryMessages: tt.limit}
+
+			req, err := runner.prepareRequest(context.Background(), NewAgent("TestAgent"), config, nil, history)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var roles []string
+			for _, msg := range req.Messages {
+				roles = append(roles, messageRole(msg))
+			}
+			if !reflect.DeepEqual(roles, tt.want) {
+				t.Errorf("expected roles %v, got %v", tt.want, roles)
+			}
+		})
+	}
+}
+
+func TestPrepareRequest_MaxHistoryTokens(t *testing.T) {
+	toolCall := openai.ChatCompletionMessage{
+		Role: "assistant",
+		ToolCalls: []openai.ChatCompletionMessageToolCall{
+			{ID: "call_1", Type: "function", Function: openai.ChatCompletionMessageToolCallFunction{Name: "a"}},
+		},
+	}.ToParam()
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("custom system"),
+		openai.UserMessage(strings.Repeat("old ", 100)),
+		toolCall,
+		openai.ToolMessage(strings.Repeat("result ", 50), "call_1"),
+		openai.UserMessage("latest"),
+	}
+	size := func(msgs ...openai.ChatCompletionMessageParamUnion) int {
+		total := 0
+		for _, msg := range msgs {
+			total += estimateTokens(msg)
+		}
+		return total
+	}
+
+	tests := []struct {
+		name        string
+		maxMessages int
+		maxTokens   int
+		want        []string
+	}{
+		{name: "fits", maxTokens: size(history...), want: []string{"system", "user", "assistant", "tool", "user"}},
+		{name: "drops oldest", maxTokens: size(history[0], history[2], history[3], history[4]), want: []string{"system", "assistant", "tool", "user"}},
+		{name: "drops tool results with their call", maxTokens: size(history[0], history[3], history[4]), want: []string{"system", "user"}},
+		{name: "keeps latest message", maxTokens: 1, want: []string{"system", "user"}},
+		{name: "combined with message cap", maxMessages: 3, maxTokens: size(history...), want: []string{"system", "assistant", "tool", "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			config := &RunConfig{MaxHistoryMessages: tt.maxMessages, MaxHistoryTokens: tt.maxTokens}
+
+			req, err := runner.prepareRequest(context.Background(), NewAgent("TestAgent"), config, nil, history)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var roles []string
+			for _, msg := range req.Messages {
+				roles = append(roles, messageRole(msg))
+			}
+			if !reflect.DeepEqual(roles, tt.want) {
+				t.Errorf("expected roles %v, got %v", tt.want, roles)
+			}
+		})
+	}
+}
+
+// messageRole returns the role of a message param.
+func messageRole(msg openai.ChatCompletionMessageParamUnion) string {
+	switch {
+	case msg.OfSystem != nil:
+		return "system"
+	case msg.OfDeveloper != nil:
+		return "developer"
+	case msg.OfUser != nil:
+		return "user"
+	case msg.OfAssistant != nil:
+		return "assistant"
+	case msg.OfTool != nil:
+		return "tool"
+	default:
+		return ""
+	}
+}
+
+func TestPrepareRequest_Examples(t *testing.T) {
+	agent := NewAgent("Classifier")
+	agent.Instructions = "Classify sentiment."
+	agent.Examples = []Example{
+		{User: "I love it", Assistant: "positive"},
+		{User: "It broke", Assistant: "negative"},
+	}
+
+	tests := []struct {
+		name    string
+		history []openai.ChatCompletionMessageParamUnion
+		want    []string
+	}{
+		{
+			name:    "after injected system message",
+			history: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Meh")},
+			want:    []string{"system", "user", "assistant", "user", "assistant", "user"},
+		},
+		{
+			name: "after caller's system message",
+			history: []openai.ChatCompletionMessageParamUnion{
+				openai.DeveloperMessage("custom"),
+				openai.UserMessage("Meh"),
+			},
+			want: []string{"developer", "user", "assistant", "user", "assistant", "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			req, err := runner.prepareRequest(context.Background(), agent, &RunConfig{}, nil, tt.history)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var roles []string
+			for _, msg := range req.Messages {
+				roles = append(roles, messageRole(msg))
+			}
+			if !reflect.DeepEqual(roles, tt.want) {
+				t.Errorf("expected roles %v, got %v", tt.want, roles)
+			}
+			if got := req.Messages[1].OfUser.Content.OfString.Value; got != "I love it" {
+				t.Errorf("expected first example, got %q", got)
+			}
+		})
+	}
+}
+
+func TestRun_ExamplesNotInHistory(t *testing.T) {
+	runner, _ := newMockRunner(t, textCompletion("neutral"))
+	agent := NewAgent("Classifier")
+	agent.Examples = []Example{{User: "I love it", Assistant: "positive"}}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Meh")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(result.Messages) != 2 {
+		t.Errorf("expected examples to stay out of the history, got %d messages", len(result.Messages))
+	}
+}
+
+func TestPrepareRequest_DisableStrictOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		config     *RunConfig
+		wantStrict bool
+	}{
+		{name: "strict schema", strict: true, config: &RunConfig{}, wantStrict: true},
+		{name: "non-strict schema", strict: false, config: &RunConfig{}, wantStrict: false},
+		{name: "disabled by config", strict: true, config: &RunConfig{DisableStrictOutput: true}, wantStrict: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.ResponseFormat = jsonschema.JSONSchema("answer", jsonschema.Object().
+				WithProperty("value", jsonschema.String()).
+				WithRequired("value"))
+			agent.ResponseFormat.JSONSchema.Strict = tt.strict
+			history := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}
+
+			req, err := runner.prepareRequest(context.Background(), agent, tt.config, nil, history)
+			if err != nil {
+				t.Fatalf("prepareRequest failed: %v", err)
+			}
+
+			if got := req.ResponseFormat.OfJSONSchema.JSONSchema.Strict.Value; got != tt.wantStrict {
+				t.Errorf("expected strict=%v, got %v", tt.wantStrict, got)
+			}
+		})
+	}
+}
+
+func TestRun_ToolResultExtraMessages(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(
+			mockToolCall{ID: "call_a", Name: "retrieve", Arguments: `{}`},
+			mockToolCall{ID: "call_b", Name: "retrieve", Arguments: `{}`},
+		),
+		textCompletion("answer"),
+	)
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("retrieve", "Retrieve docs", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return ToolResult{
+				Output:   "found 1 document",
+				Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("Document: Go is fun.")},
+			}, nil
+		}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("search")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if got := result.Steps[0].ToolCalls[0].Result; got != "found 1 document" {
+		t.Errorf("expected unwrapped tool result, got %v", got)
+	}
+
+	// user, assistant, tool, tool, extra, extra
+	sent := mock.Requests()[1]["messages"].([]any)
+	var roles []string
+	for _, m := range sent {
+		roles = append(roles, m.(map[string]any)["role"].(string))
+	}
+	want := []string{"system", "user", "assistant", "tool", "tool", "user", "user"}
+	if fmt.Sprint(roles) != fmt.Sprint(want) {
+		t.Errorf("expected roles %v, got %v", want, roles)
+	}
+}
+
+func TestRun_ContextVariablesSeededAndSharedAcrossHandoff(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "transfer", Arguments: `{}`}),
+		toolCallCompletion(mockToolCall{Name: "inspect", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	var seen ContextVariables
+	second := NewAgent("Second")
+	second.Tools = []Tool{
+		FunctionTool("inspect", "Inspect state", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+			seen = ContextVariables{}
+			for k, v := range vars {
+				seen[k] = v
+			}
+			return "ok", nil
+		}),
+	}
+
+	first := NewAgent("First")
+	first.Tools = []Tool{
+		FunctionTool("transfer", "Transfer", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+			vars["visited"] = "First"
+			return second, nil
+		}),
+	}
+
+	config := DefaultRunConfig()
+	config.ContextVariables = ContextVariables{"tenant": "seed", "user": "seed"}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	result, err := runner.Run(context.Background(), first, messages, ContextVariables{"user": "caller"}, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Agent != second {
+		t.Fatalf("expected final agent Second, got %s", result.Agent.Name)
+	}
+	if seen["tenant"] != "seed" {
+		t.Errorf("expected seeded tenant, got %v", seen["tenant"])
+	}
+	if seen["user"] != "caller" {
+		t.Errorf("expected caller value to win over seed, got %v", seen["user"])
+	}
+	if seen["visited"] != "First" {
+		t.Errorf("expected state from first agent after handoff, got %v", seen["visited"])
+	}
+}
+
+func TestRun_HandoffSwitchesResponseFormat(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "transfer", Arguments: `{}`}),
+		textCompletion(`{"answer":"42"}`),
+	)
+
+	answerFormat := jsonschema.JSONSchema("answer",
+		jsonschema.Object().WithProperty("answer", jsonschema.String()).WithRequired("answer"))
+	triageFormat := jsonschema.JSONSchema("triage",
+		jsonschema.Object().WithProperty("route", jsonschema.String()).WithRequired("route"))
+
+	specialist := NewAgent("Specialist")
+	specialist.ResponseFormat = answerFormat
+
+	triage := NewAgent("Triage")
+	triage.ResponseFormat = triageFormat
+	triage.Tools = []Tool{
+		FunctionTool("transfer", "Transfer", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+			return specialist, nil
+		}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("question")}
+	result, err := runner.Run(context.Background(), triage, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.ResponseFormat != answerFormat {
+		t.Error("expected result to carry the final agent's response format")
+	}
+
+	schemaName := func(req map[string]any) string {
+		rf := req["response_format"].(map[string]any)
+		return rf["json_schema"].(map[string]any)["name"].(string)
+	}
+	requests := mock.Requests()
+	if got := schemaName(requests[0]); got != "triage" {
+		t.Errorf("expected first turn schema triage, got %s", got)
+	}
+	if got := schemaName(requests[1]); got != "answer" {
+		t.Errorf("expected post-handoff schema answer, got %s", got)
+	}
+}
+
+func TestRun_ToolResultFormatter(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "add", Arguments: `{"a": 1, "b": 2}`}),
+		textCompletion("3"),
+	)
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("add", "Add numbers", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+			return args["a"].(float64) + args["b"].(float64), nil
+		}),
+	}
+
+	config := DefaultRunConfig()
+	config.ToolResultFormatter = func(call ToolCall) string {
+		return fmt.Sprintf("[%s] %v", call.ToolName, call.Result)
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("1+2")}
+	if _, err := runner.Run(context.Background(), agent, messages, nil, config); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	sent := mock.Requests()[1]["messages"].([]any)
+	toolMsg := sent[len(sent)-1].(map[string]any)
+	if toolMsg["content"] != "[add] 3" {
+		t.Errorf("expected formatted tool content, got %v", toolMsg["content"])
+	}
+}
+
+func TestRun_StructuredToolResult(t *testing.T) {
+	type weather struct {
+		City  string  `json:"city"`
+		TempC float64 `json:"temp_c"`
+	}
+
+	tests := []struct {
+		name   string
+		result any
+		want   string
+	}{
+		{name: "string", result: "sunny", want: "sunny"},
+		{name: "struct", result: weather{City: "Paris", TempC: 21.5}, want: `{"city":"Paris","temp_c":21.5}`},
+		{name: "map", result: map[string]any{"city": "Paris", "tags": []string{"warm", "dry"}}, want: `{"city":"Paris","tags":["warm","dry"]}`},
+		// Falls back to %v, whose channel address varies
+		{name: "unmarshalable", result: map[string]any{"updates": make(chan int)}, want: "map[updates:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, mock := newMockRunner(t,
+				toolCallCompletion(mockToolCall{Name: "weather", Arguments: `{}`}),
+				textCompletion("done"),
+			)
+
+			agent := NewAgent("TestAgent")
+			agent.Tools = []Tool{
+				FunctionTool("weather", "Get the weather", nil, func(map[string]any, ContextVariables) (any, error) {
+					return tt.result, nil
+				}),
+			}
+
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("weather?")}
+			result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			sent := mock.Requests()[1]["messages"].([]any)
+			content, _ := sent[len(sent)-1].(map[string]any)["content"].(string)
+			if !strings.HasPrefix(content, tt.want) {
+				t.Errorf("expected tool content %s, got %s", tt.want, content)
+			}
+			if got := result.Steps[0].ToolCalls[0].Result; fmt.Sprint(got) != fmt.Sprint(tt.result) {
+				t.Errorf("expected the typed result to be recorded, got %v", got)
+			}
+		})
+	}
+}
+
+func TestRun_ToolTimeout(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "slow", Arguments: `{}`}),
+		textCompletion("sorry"),
+	)
+
+	agent := NewAgent("Assistant")
+	slow := FunctionToolWithContext("slow", "Slow", nil,
+		func(ctx context.Context, _ map[string]any, _ ContextVariables) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	slow.Timeout = 10 * time.Millisecond
+	agent.Tools = []Tool{slow}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var execErr *ToolExecutionError
+	if callErr := result.Steps[0].ToolCalls[0].Error; !errors.As(callErr, &execErr) || !errors.Is(callErr, ErrToolTimeout) {
+		t.Errorf("expected tool execution error wrapping ErrToolTimeout, got %v", callErr)
+	}
+	msgs, _ := mock.Requests()[1]["messages"].([]any)
+	toolMsg, _ := msgs[len(msgs)-1].(map[string]any)
+	if toolMsg["content"] != "Error: tool slow timed out after 10ms" {
+		t.Errorf("unexpected tool message %v", toolMsg["content"])
+	}
+}
+
+func TestRun_ToolHooks(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(
+			mockToolCall{Name: "read", Arguments: `{"path": "a.txt"}`},
+			mockToolCall{Name: "delete", Arguments: `{"path": "a.txt"}`},
+			mockToolCall{Name: "secret", Arguments: `{}`},
+		),
+		textCompletion("done"),
+	)
+
+	var ran, started, ended []string
+	tool := func(name string) Tool {
+		return FunctionTool(name, name, nil, func(map[string]any, ContextVariables) (any, error) {
+			ran = append(ran, name)
+			return name + " ok", nil
+		})
+	}
+	errDenied := errors.New("not allowed")
+	errRedacted := errors.New("redacted")
+
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{tool("read"), tool("delete"), tool("secret")}
+	agent.ParallelToolCalls = false // keep the hook order deterministic
+	agent.OnToolStart = func(_ context.Context, name, args string) error {
+		started = append(started, name+" "+args)
+		if name == "delete" {
+			return errDenied
+		}
+		return nil
+	}
+	agent.OnToolEnd = func(_ context.Context, name string, result any, err error) error {
+		ended = append(ended, fmt.Sprintf("%s=%v/%v", name, result, err))
+		if name == "secret" {
+			return errRedacted
+		}
+		return nil
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if want := []string{"read", "secret"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("expected tools %v to run, got %v", want, ran)
+	}
+	if len(started) != 3 || started[0] != `read {"path": "a.txt"}` {
+		t.Errorf("unexpected OnToolStart calls %v", started)
+	}
+	if want := []string{"read=read ok/<nil>", "secret=secret ok/<nil>"}; !reflect.DeepEqual(ended, want) {
+		t.Errorf("expected OnToolEnd calls %v, got %v", want, ended)
+	}
+
+	calls := result.Steps[0].ToolCalls
+	if calls[0].Error != nil {
+		t.Errorf("expected read to succeed, got %v", calls[0].Error)
+	}
+	if !errors.Is(calls[1].Error, ErrToolSkipped) || !errors.Is(calls[1].Error, errDenied) || calls[1].Attempts != 0 {
+		t.Errorf("expected delete to be skipped, got %v (%d attempts)", calls[1].Error, calls[1].Attempts)
+	}
+	if !errors.Is(calls[2].Error, errRedacted) {
+		t.Errorf("expected OnToolEnd error on secret, got %v", calls[2].Error)
+	}
+
+	msgs, _ := mock.Requests()[1]["messages"].([]any)
+	skipped, _ := msgs[len(msgs)-2].(map[string]any)
+	if skipped["content"] != "Error: tool delete was not run: not allowed" {
+		t.Errorf("unexpected tool message %v", skipped["content"])
+	}
+}
+
+func TestRun_ParallelToolCalls(t *testing.T) {
+	for _, parallel := range []bool{true, false} {
+		t.Run(fmt.Sprintf("parallel=%v", parallel), func(t *testing.T) {
+			runner, mock := newMockRunner(t,
+				toolCallCompletion(
+					mockToolCall{ID: "call_1", Name: "slow", Arguments: `{}`},
+					mockToolCall{ID: "call_2", Name: "fast", Arguments: `{}`},
+				),
+				textCompletion("done"),
+			)
+
+			// slow finishes only after fast has started, unless they run one by one
+			fastStarted := make(chan struct{})
+			agent := NewAgent("Assistant")
+			agent.ParallelToolCalls = parallel
+			agent.Tools = []Tool{
+				FunctionTool("slow", "Slow", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+					select {
+					case <-fastStarted:
+						vars["overlapped"] = true
+					case <-time.After(100 * time.Millisecond):
+					}
+					vars["slow"] = "done"
+					return "slow result", nil
+				}),
+				FunctionTool("fast", "Fast", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+					close(fastStarted)
+					vars["fast"] = "done"
+					delete(vars, "stale")
+					return "fast result", nil
+				}),
+			}
+
+			vars := ContextVariables{"stale": true}
+			result, err := runner.Run(context.Background(), agent, []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}, vars, nil)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if _, overlapped := vars["overlapped"]; overlapped != parallel {
+				t.Errorf("expected overlapping calls %v, got %v", parallel, overlapped)
+			}
+			if vars["slow"] != "done" || vars["fast"] != "done" {
+				t.Errorf("expected both tools to update the context variables, got %v", vars)
+			}
+			if _, ok := vars["stale"]; ok {
+				t.Error("expected the deleted variable to stay deleted")
+			}
+
+			calls := result.Steps[0].ToolCalls
+			if calls[0].Result != "slow result" || calls[1].Result != "fast result" {
+				t.Errorf("expected results in call order, got %v, %v", calls[0].Result, calls[1].Result)
+			}
+			msgs, _ := mock.Requests()[1]["messages"].([]any)
+			first, _ := msgs[len(msgs)-2].(map[string]any)
+			second, _ := msgs[len(msgs)-1].(map[string]any)
+			if first["tool_call_id"] != "call_1" || second["tool_call_id"] != "call_2" {
+				t.Errorf("expected tool messages in call order, got %v, %v", first["tool_call_id"], second["tool_call_id"])
+			}
+		})
+	}
+}
+
+func TestRun_UnformattableToolResult(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "stream", Arguments: `{}`}),
+		textCompletion("sorry"),
+	)
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("stream", "Open a stream", nil, func(map[string]any, ContextVariables) (any, error) {
+			return map[string]any{"events": make(chan int)}, nil
+		}),
+	}
+
+	// A formatter that insists on JSON cannot encode the channel
+	config := DefaultRunConfig()
+	config.ToolResultFormatter = func(call ToolCall) string {
+		data, err := json.Marshal(call.Result)
+		if err != nil {
+			panic(err)
+		}
+		return string(data)
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("stream")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	sent := mock.Requests()[1]["messages"].([]any)
+	toolMsg := sent[len(sent)-1].(map[string]any)
+	if toolMsg["content"] != "Error: tool stream returned a result that could not be formatted" {
+		t.Errorf("expected fallback tool content, got %v", toolMsg["content"])
+	}
+	call := result.Steps[0].ToolCalls[0]
+	if !errors.Is(call.Error, ErrUnformattableResult) {
+		t.Errorf("expected ErrUnformattableResult on the tool call, got %v", call.Error)
+	}
+}
+
+func TestRun_ToolResultTransform(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "search", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	search := FunctionTool("search", "Search", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+		return []string{"a", "b", "c", "d", "e"}, nil
+	})
+	search.ResultTransform = func(result any) any {
+		return result.([]string)[:2]
+	}
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{search}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("find")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	sent := mock.Requests()[1]["messages"].([]any)
+	toolMsg := sent[len(sent)-1].(map[string]any)
+	if toolMsg["content"] != `["a","b"]` {
+		t.Errorf("expected transformed tool content, got %v", toolMsg["content"])
+	}
+	if got := result.Steps[0].ToolCalls[0].Result.([]string); len(got) != 5 {
+		t.Errorf("expected full result to be recorded, got %v", got)
+	}
+}
+
+func TestRun_CacheableTool(t *testing.T) {
+	tests := []struct {
+		name       string
+		cacheable  bool
+		wantCalls  int
+		wantCached []bool
+	}{
+		{name: "cacheable", cacheable: true, wantCalls: 2, wantCached: []bool{false, true, false}},
+		{name: "not cacheable", wantCalls: 3, wantCached: []bool{false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner, mock := newMockRunner(t,
+				toolCallCompletion(mockToolCall{Name: "km_to_miles", Arguments: `{"km": 5}`}),
+				toolCallCompletion(mockToolCall{Name: "km_to_miles", Arguments: `{ "km":5 }`}),
+				toolCallCompletion(mockToolCall{Name: "km_to_miles", Arguments: `{"km": 6}`}),
+				textCompletion("done"),
+			)
+
+			calls := 0
+			convert := FunctionTool("km_to_miles", "Convert kilometers to miles", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+				calls++
+				return args["km"].(float64) * 0.621371, nil
+			})
+			convert.Cacheable = tt.cacheable
+			agent := NewAgent("TestAgent")
+			agent.Tools = []Tool{convert}
+
+			messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("convert")}
+			result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("expected the callback to run %d times, got %d", tt.wantCalls, calls)
+			}
+			for i, want := range tt.wantCached {
+				if got := result.Steps[i].ToolCalls[0].Cached; got != want {
+					t.Errorf("step %d: expected Cached=%v, got %v", i+1, want, got)
+				}
+			}
+
+			// The cached result is sent to the model like a fresh one
+			sent := mock.Requests()[2]["messages"].([]any)
+			if content := sent[len(sent)-1].(map[string]any)["content"]; content != "3.106855" {
+				t.Errorf("expected the cached result, got %v", content)
+			}
+		})
+	}
+}
+
+func TestRun_CacheableToolSameTurn(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(
+			mockToolCall{Name: "km_to_miles", Arguments: `{"km": 5}`},
+			mockToolCall{Name: "km_to_miles", Arguments: `{"km": 5}`},
+		),
+		toolCallCompletion(mockToolCall{Name: "km_to_miles", Arguments: `{"km": 5}`}),
+		textCompletion("done"),
+	)
+
+	var calls atomic.Int32
+	convert := FunctionTool("km_to_miles", "Convert kilometers to miles", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+		calls.Add(1)
+		return args["km"].(float64) * 0.621371, nil
+	})
+	convert.Cacheable = true
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{convert}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("convert")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Both calls of the first turn run, the next turn reuses their result
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected the callback to run 2 times, got %d", n)
+	}
+	for i, call := range result.Steps[0].ToolCalls {
+		if call.Cached {
+			t.Errorf("call %d of the first turn should not be cached", i+1)
+		}
+	}
+	if !result.Steps[1].ToolCalls[0].Cached {
+		t.Error("expected the second turn to be answered from cache")
+	}
+}
+
+func TestRun_SummarizeToolResult(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "fetch", Arguments: `{}`}),
+		textCompletion("short summary"),
+		textCompletion("done"),
+	)
+
+	fetch := FunctionTool("fetch", "Fetch a document", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+		return "a very long document", nil
+	})
+	fetch.SummarizeResult = true
+	agent := NewAgent("Reader")
+	agent.Tools = []Tool{fetch}
+
+	config := DefaultRunConfig()
+	config.Summarizer = NewAgent("Summarizer").WithModel(ModelGPT4oMini)
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("read it")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	reqs := mock.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 LLM calls, got %d", len(reqs))
+	}
+	if reqs[1]["model"] != ModelGPT4oMini {
+		t.Errorf("expected summarizer model, got %v", reqs[1]["model"])
+	}
+	sent := reqs[2]["messages"].([]any)
+	if toolMsg := sent[len(sent)-1].(map[string]any); toolMsg["content"] != "short summary" {
+		t.Errorf("expected summary as tool content, got %v", toolMsg["content"])
+	}
+	if got := result.Steps[0].ToolCalls[0].Result; got != "a very long document" {
+		t.Errorf("expected raw result to be recorded, got %v", got)
+	}
+	if result.Usage.TotalTokens != 45 || result.Steps[0].Usage.TotalTokens != 30 {
+		t.Errorf("expected summarization usage to be counted, got run %d, step %d",
+			result.Usage.TotalTokens, result.Steps[0].Usage.TotalTokens)
+	}
+}
+
+func TestRun_ConsolidateToolResults(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(
+			mockToolCall{ID: "call_a", Name: "echo", Arguments: `{"v":"one"}`},
+			mockToolCall{ID: "call_b", Name: "echo", Arguments: `{"v":"two"}`},
+		),
+		textCompletion("done"),
+	)
+
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("echo", "Echo", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+			return args["v"], nil
+		}),
+	}
+	config := DefaultRunConfig()
+	config.ConsolidateToolResults = true
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("go")}
+	if _, err := runner.Run(context.Background(), agent, messages, nil, config); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	sent := mock.Requests()[1]["messages"].([]any)
+	var callIDs []string
+	answered := make(map[string]int)
+	var toolMsgs []map[string]any
+	for _, m := range sent {
+		msg := m.(map[string]any)
+		switch msg["role"] {
+		case "assistant":
+			calls, _ := msg["tool_calls"].([]any)
+			for _, c := range calls {
+				callIDs = append(callIDs, c.(map[string]any)["id"].(string))
+			}
+		case "tool":
+			answered[msg["tool_call_id"].(string)]++
+			toolMsgs = append(toolMsgs, msg)
+		}
+	}
+
+	// Every call ID must be answered exactly once for the API to accept the history
+	if len(callIDs) != 2 || len(answered) != len(callIDs) {
+		t.Fatalf("expected each of the calls %v to be answered, got %v", callIDs, answered)
+	}
+	for _, id := range callIDs {
+		if answered[id] != 1 {
+			t.Errorf("expected %s to be answered once, got %d", id, answered[id])
+		}
+	}
+	if toolMsgs[0]["tool_call_id"] != "call_a" {
+		t.Errorf("expected the consolidated message under the first call ID, got %v", toolMsgs[0]["tool_call_id"])
+	}
+	if stub := toolMsgs[1]["content"]; stub != "See consolidated result in call_a" {
+		t.Errorf("unexpected stub for call_b: %v", stub)
+	}
+	parts := toolMsgs[0]["content"].([]any)
+	want := []string{"[call_a] echo: one", "[call_b] echo: two"}
+	if len(parts) != len(want) {
+		t.Fatalf("expected %d content blocks, got %d", len(want), len(parts))
+	}
+	for i, w := range want {
+		if got := parts[i].(map[string]any)["text"]; got != w {
+			t.Errorf("block %d: expected %q, got %v", i, w, got)
+		}
+	}
+}
+
+func TestPrepareRequest_ParallelToolCalls(t *testing.T) {
+	boolPtr := func(b bool) *bool { return &b }
+	tools := []openai.ChatCompletionToolParam{{Function: openai.FunctionDefinitionParam{Name: "t"}}}
+
+	tests := []struct {
+		name     string
+		agentVal bool
+		config   *bool
+		tools    []openai.ChatCompletionToolParam
+		wantSet  bool
+		want     bool
+	}{
+		{name: "agent true", agentVal: true, tools: tools, wantSet: true, want: true},
+		{name: "agent false", agentVal: false, tools: tools, wantSet: true, want: false},
+		{name: "config false overrides agent true", agentVal: true, config: boolPtr(false), tools: tools, wantSet: true, want: false},
+		{name: "config true overrides agent false", agentVal: false, config: boolPtr(true), tools: tools, wantSet: true, want: true},
+		{name: "no tools leaves unset", agentVal: true, wantSet: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewRunner(&openai.Client{})
+			agent := NewAgent("TestAgent")
+			agent.ParallelToolCalls = tt.agentVal
+			config := &RunConfig{ParallelToolCalls: tt.config}
+
+			req, err := runner.prepareRequest(context.Background(), agent, config, tt.tools, nil)
+			if err != nil {
+				t.Fatalf("prepareRequest failed: %v", err)
+			}
+
+			if req.ParallelToolCalls.Valid() != tt.wantSet {
+				t.Fatalf("expected ParallelToolCalls set=%v, got %v", tt.wantSet, req.ParallelToolCalls.Valid())
+			}
+			if tt.wantSet && req.ParallelToolCalls.Value != tt.want {
+				t.Errorf("expected ParallelToolCalls=%v, got %v", tt.want, req.ParallelToolCalls.Value)
+			}
+		})
+	}
+}
+
 // Integration-style test that would require OpenAI API
 // Commented out as it requires actual API access
 /*
@@ -0,0 +1,52 @@
+package agents
+
+import "strings"
+
+// ScratchpadKey is the context variable holding the run's scratchpad, a
+// running log of notes that tools keep across turns, e.g. the intermediate
+// findings of a ReAct-style agent. Set RunConfig.InjectScratchpad to show
+// it to the model on every turn.
+const ScratchpadKey = "_scratchpad"
+
+// scratchpadPrefix introduces the scratchpad note sent to the model
+const scratchpadPrefix = "Scratchpad (notes from earlier steps):\n"
+
+// Scratchpad returns the notes appended to the scratchpad, one per line.
+func (c ContextVariables) Scratchpad() string {
+	notes, _ := c[ScratchpadKey].(string)
+	return notes
+}
+
+// AppendScratchpad adds a note to the scratchpad on a new line. Empty notes
+// are ignored.
+func (c ContextVariables) AppendScratchpad(note string) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return
+	}
+	if notes := c.Scratchpad(); notes != "" {
+		note = notes + "\n" + note
+	}
+	c[ScratchpadKey] = note
+}
+
+// mergeScratchpad appends to the scratchpad of vars the notes a tool
+// appended to its copy of snapshot. It reports false if the tool did more
+// than append, leaving the change to be merged like any other key.
+func mergeScratchpad(vars, snapshot, changed ContextVariables) bool {
+	notes, ok := changed[ScratchpadKey].(string)
+	if !ok {
+		return false
+	}
+	added, ok := strings.CutPrefix(notes, snapshot.Scratchpad())
+	if !ok {
+		return false
+	}
+	vars.AppendScratchpad(added)
+	return true
+}
+
+// ClearScratchpad removes all notes from the scratchpad.
+func (c ContextVariables) ClearScratchpad() {
+	delete(c, ScratchpadKey)
+}
@@ -0,0 +1,102 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestContextVariables_Scratchpad(t *testing.T) {
+	vars := ContextVariables{}
+	vars.AppendScratchpad("first")
+	vars.AppendScratchpad("  ")
+	vars.AppendScratchpad("second\n")
+
+	if got := vars.Scratchpad(); got != "first\nsecond" {
+		t.Errorf("unexpected scratchpad %q", got)
+	}
+	vars.ClearScratchpad()
+	if got := vars.Scratchpad(); got != "" {
+		t.Errorf("expected empty scratchpad, got %q", got)
+	}
+}
+
+func TestRun_ParallelScratchpadNotes(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(
+			mockToolCall{ID: "call_1", Name: "note", Arguments: `{"text":"from the first call"}`},
+			mockToolCall{ID: "call_2", Name: "note", Arguments: `{"text":"from the second call"}`},
+			mockToolCall{ID: "call_3", Name: "note", Arguments: `{"text":"from the third call"}`},
+		),
+		textCompletion("done"),
+	)
+
+	// Hold every call until all have started, so they write concurrently
+	var started sync.WaitGroup
+	started.Add(3)
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionTool("note", "Takes a note", nil, func(args map[string]any, vars ContextVariables) (any, error) {
+			started.Done()
+			started.Wait()
+			vars.AppendScratchpad(args["text"].(string))
+			return "ok", nil
+		}),
+	}
+
+	vars := ContextVariables{}
+	vars.AppendScratchpad("earlier note")
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("take notes")}
+	if _, err := runner.Run(context.Background(), agent, messages, vars, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := "earlier note\nfrom the first call\nfrom the second call\nfrom the third call"
+	if got := vars.Scratchpad(); got != want {
+		t.Errorf("expected every call's note in call order, got %q", got)
+	}
+}
+
+func TestRun_InjectScratchpad(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		toolCallCompletion(mockToolCall{Name: "note", Arguments: `{}`}),
+		textCompletion("done"),
+	)
+
+	agent := NewAgent("Assistant")
+	agent.Tools = []Tool{
+		FunctionTool("note", "Takes a note", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+			vars.AppendScratchpad("checked the weather")
+			return "ok", nil
+		}),
+	}
+
+	config := &RunConfig{MaxTurns: 5, InjectScratchpad: true}
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("plan my day")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, config)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	lastContent := func(req map[string]any) string {
+		msgs, _ := req["messages"].([]any)
+		last, _ := msgs[len(msgs)-1].(map[string]any)
+		content, _ := last["content"].(string)
+		return content
+	}
+	reqs := mock.Requests()
+	if got := lastContent(reqs[0]); got != "plan my day" {
+		t.Errorf("expected no note before the scratchpad is written, got %q", got)
+	}
+	if got := lastContent(reqs[1]); !strings.HasSuffix(got, "checked the weather") {
+		t.Errorf("expected scratchpad note on the second turn, got %q", got)
+	}
+	for _, msg := range result.Messages {
+		if msg.OfSystem != nil {
+			t.Error("expected the scratchpad note to stay out of the history")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/openai/openai-go"
+)
+
+// RunStreamTo executes the agent loop like Run, but streams the model's text
+// as it is generated, writing each delta to w (e.g. os.Stdout). Tool calls and
+// handoffs are executed transparently between turns. The returned Result is
+// the same as Run would produce.
+func (r *Runner) RunStreamTo(
+	ctx context.Context,
+	agent *Agent,
+	messages []openai.ChatCompletionMessageParamUnion,
+	contextParams ContextVariables,
+	config *RunConfig,
+	w io.Writer,
+) (*Result, error) {
+	var onToolCall func(context.Context, ToolCallRequested)
+	if config != nil {
+		onToolCall = config.OnToolCallRequested
+	}
+	return r.run(ctx, agent, messages, contextParams, config, r.streamCompletion(w, onToolCall), r.newCompletion)
+}
+
+// ToolCallRequested describes a tool call the model has finished streaming
+// but that has not been executed yet.
+type ToolCallRequested struct {
+	// ID is the tool call ID assigned by the model
+	ID string
+
+	// Name is the name of the requested tool
+	Name string
+
+	// Arguments is the complete JSON arguments string
+	Arguments string
+}
+
+// streamCompletion returns a completionFunc that streams the response,
+// forwarding content deltas to w and accumulating the full completion.
+// onToolCall, if set, is called for each tool call once it is assembled.
+func (r *Runner) streamCompletion(w io.Writer, onToolCall func(context.Context, ToolCallRequested)) completionFunc {
+	return func(ctx context.Context, req openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+		req.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.Bool(true),
+		}
+
+		if r.Client == nil {
+			return nil, ErrNoClient
+		}
+		stream := r.Client.Chat.Completions.NewStreaming(ctx, req, requestOptions(ctx)...)
+		defer stream.Close()
+
+		var acc openai.ChatCompletionAccumulator
+		requested := 0
+		for stream.Next() {
+			chunk := stream.Current()
+			acc.AddChunk(chunk)
+
+			if tc, ok := acc.JustFinishedToolCall(); ok {
+				requested = notifyToolCalls(ctx, onToolCall, &acc, requested, tc.Index+1)
+			}
+
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				if _, err := io.WriteString(w, chunk.Choices[0].Delta.Content); err != nil {
+					return nil, fmt.Errorf("failed to write stream output: %w", err)
+				}
+			}
+		}
+		if err := stream.Err(); err != nil {
+			return nil, err
+		}
+
+		// The last tool call is only flagged as finished by a later chunk,
+		// which providers may not send
+		if len(acc.Choices) > 0 {
+			notifyToolCalls(ctx, onToolCall, &acc, requested, len(acc.Choices[0].Message.ToolCalls))
+		}
+
+		return &acc.ChatCompletion, nil
+	}
+}
+
+// notifyToolCalls reports the accumulated tool calls from index from up to
+// (excluding) to, and returns the index of the next call to report.
+func notifyToolCalls(
+	ctx context.Context,
+	onToolCall func(context.Context, ToolCallRequested),
+	acc *openai.ChatCompletionAccumulator,
+	from, to int,
+) int {
+	calls := acc.Choices[0].Message.ToolCalls
+	for i := from; i < to && i < len(calls); i++ {
+		if onToolCall != nil {
+			onToolCall(ctx, ToolCallRequested{
+				ID:        calls[i].ID,
+				Name:      calls[i].Function.Name,
+				Arguments: calls[i].Function.Arguments,
+			})
+		}
+	}
+	return max(from, to)
+}
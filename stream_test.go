@@ -0,0 +1,152 @@
+package agents
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestRunStreamTo(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		streamBody("tool_calls",
+			map[string]any{"role": "assistant", "tool_calls": []map[string]any{{
+				"index": 0, "id": "call_1", "type": "function",
+				"function": map[string]any{"name": "get_weather", "arguments": ""},
+			}}},
+			map[string]any{"tool_calls": []map[string]any{{
+				"index": 0, "function": map[string]any{"arguments": `{"city":"Paris"}`},
+			}}},
+		),
+		streamBody("stop",
+			map[string]any{"role": "assistant", "content": "It is "},
+			map[string]any{"content": "sunny."},
+		),
+	)
+
+	var gotCity string
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("get_weather", "Weather", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+			gotCity, _ = args["city"].(string)
+			return "sunny", nil
+		}),
+	}
+
+	var out strings.Builder
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("weather in Paris?")}
+	result, err := runner.RunStreamTo(context.Background(), agent, messages, nil, nil, &out)
+	if err != nil {
+		t.Fatalf("RunStreamTo failed: %v", err)
+	}
+
+	if out.String() != "It is sunny." {
+		t.Errorf("expected streamed text %q, got %q", "It is sunny.", out.String())
+	}
+	if result.FinalOutput != "It is sunny." {
+		t.Errorf("expected FinalOutput to match streamed text, got %q", result.FinalOutput)
+	}
+	if gotCity != "Paris" {
+		t.Errorf("expected tool to receive city=Paris, got %q", gotCity)
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("expected 30 total tokens, got %d", result.Usage.TotalTokens)
+	}
+
+	req := mock.Requests()[0]
+	if req["stream"] != true {
+		t.Error("expected streaming request")
+	}
+}
+
+func TestRunStreamTo_ToolCallRequested(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		streamBody("tool_calls",
+			map[string]any{"role": "assistant", "tool_calls": []map[string]any{{
+				"index": 0, "id": "call_1", "type": "function",
+				"function": map[string]any{"name": "get_weather", "arguments": `{"city":`},
+			}}},
+			map[string]any{"tool_calls": []map[string]any{{
+				"index": 0, "function": map[string]any{"arguments": `"Paris"}`},
+			}}},
+			map[string]any{"tool_calls": []map[string]any{{
+				"index": 1, "id": "call_2", "type": "function",
+				"function": map[string]any{"name": "get_weather", "arguments": `{"city":"Rome"}`},
+			}}},
+		),
+		streamBody("stop", map[string]any{"role": "assistant", "content": "Sunny in both."}),
+	)
+
+	var events []string
+	agent := NewAgent("TestAgent")
+	agent.Tools = []Tool{
+		FunctionTool("get_weather", "Weather", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+			events = append(events, "execute "+args["city"].(string))
+			return "sunny", nil
+		}),
+	}
+	agent.ParallelToolCalls = false // keep the execution order deterministic
+	config := DefaultRunConfig()
+	config.OnToolCallRequested = func(_ context.Context, call ToolCallRequested) {
+		events = append(events, "requested "+call.ID+" "+call.Name+call.Arguments)
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("weather in Paris and Rome?")}
+	if _, err := runner.RunStreamTo(context.Background(), agent, messages, nil, config, io.Discard); err != nil {
+		t.Fatalf("RunStreamTo failed: %v", err)
+	}
+
+	want := []string{
+		`requested call_1 get_weather{"city":"Paris"}`,
+		`requested call_2 get_weather{"city":"Rome"}`,
+		"execute Paris",
+		"execute Rome",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+}
+
+func TestRunStreamTo_SummaryNotStreamed(t *testing.T) {
+	runner, mock := newMockRunner(t,
+		streamBody("tool_calls", map[string]any{"role": "assistant", "tool_calls": []map[string]any{{
+			"index": 0, "id": "call_1", "type": "function",
+			"function": map[string]any{"name": "fetch", "arguments": `{}`},
+		}}}),
+		textCompletion("short summary"),
+		streamBody("stop", map[string]any{"role": "assistant", "content": "Done reading."}),
+	)
+
+	fetch := FunctionTool("fetch", "Fetch a document", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+		return "a very long document", nil
+	})
+	fetch.SummarizeResult = true
+	agent := NewAgent("Reader")
+	agent.Tools = []Tool{fetch}
+	config := DefaultRunConfig()
+	config.Summarizer = NewAgent("Summarizer").WithModel(ModelGPT4oMini)
+
+	var out strings.Builder
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("read it")}
+	if _, err := runner.RunStreamTo(context.Background(), agent, messages, nil, config, &out); err != nil {
+		t.Fatalf("RunStreamTo failed: %v", err)
+	}
+
+	if out.String() != "Done reading." {
+		t.Errorf("expected only the agent's answer in the stream, got %q", out.String())
+	}
+	reqs := mock.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 LLM calls, got %d", len(reqs))
+	}
+	if reqs[1]["stream"] == true {
+		t.Error("expected the summarizer call not to stream")
+	}
+	sent := reqs[2]["messages"].([]any)
+	if toolMsg := sent[len(sent)-1].(map[string]any); toolMsg["content"] != "short summary" {
+		t.Errorf("expected summary as tool content, got %v", toolMsg["content"])
+	}
+}
@@ -1,10 +1,16 @@
 package agents
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"strings"
+	"time"
 
 	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
 // Tool represents a function that can be called by an agent.
@@ -18,6 +24,64 @@ type Tool struct {
 	// Callback is the function to execute when the tool is called.
 	// It receives the arguments as a map and context variables.
 	Callback func(args map[string]any, ctx ContextVariables) (any, error)
+	// CallbackWithContext is an alternative to Callback that also receives the
+	// run context, which carries the run ID and logger (see RunIDFromContext
+	// and LoggerFromContext) and the deadline of RunConfig.Timeout; a tool
+	// calling a slow service should bound its request by ctx.Deadline(). It
+	// takes precedence over Callback when both are set.
+	CallbackWithContext func(ctx context.Context, args map[string]any, vars ContextVariables) (any, error)
+	// UseNumber decodes numeric arguments as json.Number instead of float64,
+	// preserving integer precision. Read them with ArgInt and ArgFloat.
+	UseNumber bool
+	// ArgumentCoercion normalizes the decoded arguments (key style, numeric
+	// strings, defaults) before the callback runs. If nil, arguments are
+	// passed through as decoded.
+	ArgumentCoercion *ArgumentCoercion
+	// MaxRetries is how many times a failing callback is retried before the
+	// error is reported to the model. 0 disables retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry; it doubles on every
+	// further attempt. Retries stop early when the run context is done.
+	RetryBackoff time.Duration
+	// Timeout bounds each call of the callback. The callback's context is
+	// cancelled when it expires and the call fails with ErrToolTimeout, so
+	// the model can react. The callback works on a copy of the context
+	// variables that is merged back only if it finishes in time, so a
+	// callback that ignores its context and keeps running in the background
+	// cannot race with the rest of the run. 0 means no per-tool timeout.
+	Timeout time.Duration
+	// ResultTransform reduces a successful result before it is sent back to
+	// the model, e.g. keeping only the top hits of a search. The untransformed
+	// result is still recorded in ToolCall.Result.
+	ResultTransform func(result any) any
+	// SummarizeResult passes successful results through RunConfig.Summarizer
+	// before they are sent to the model, for tools returning large documents.
+	// The raw result is still recorded in ToolCall.Result.
+	SummarizeResult bool
+	// Examples are sample calls appended to the description sent to the
+	// model, which helps it pick and call ambiguous tools correctly.
+	Examples []ToolExample
+	// Cacheable marks the tool as a pure function of its arguments, such as
+	// a unit conversion. Within a run, a call with the same arguments as an
+	// earlier successful call reuses its result without running the
+	// callback or the OnToolStart and OnToolEnd hooks, and is recorded with
+	// ToolCall.Cached set. The cache is filled after each turn, so identical
+	// calls in the same turn all run. Results are never shared between runs.
+	Cacheable bool
+}
+
+// ToolExample is a sample tool call shown to the model.
+type ToolExample struct {
+	// Arguments passed to the tool in this example.
+	Arguments map[string]any
+	// Result the tool returns for these arguments. Optional.
+	Result string
+}
+
+// WithExamples returns a copy of the tool with the given examples added.
+func (t Tool) WithExamples(examples ...ToolExample) Tool {
+	t.Examples = append(t.Examples[:len(t.Examples):len(t.Examples)], examples...)
+	return t
 }
 
 // ToParam converts the Tool to an openai.ChatCompletionToolParam.
@@ -34,34 +98,147 @@ func (t Tool) ToParam() openai.ChatCompletionToolParam {
 	return openai.ChatCompletionToolParam{
 		Function: openai.FunctionDefinitionParam{
 			Name:        t.Name,
-			Description: openai.String(t.Description),
+			Description: openai.String(t.describe()),
 			Parameters:  openai.FunctionParameters(params),
 		},
 	}
 }
 
+// describe returns the description with the examples folded in.
+func (t Tool) describe() string {
+	if len(t.Examples) == 0 {
+		return t.Description
+	}
+
+	var b strings.Builder
+	b.WriteString(t.Description)
+	if t.Description != "" {
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Examples:")
+	for _, ex := range t.Examples {
+		args, err := json.Marshal(ex.Arguments)
+		if err != nil || ex.Arguments == nil {
+			args = []byte("{}")
+		}
+		fmt.Fprintf(&b, "\n- %s(%s)", t.Name, args)
+		if ex.Result != "" {
+			fmt.Fprintf(&b, " -> %s", ex.Result)
+		}
+	}
+	return b.String()
+}
+
 // Execute runs the tool's callback with the provided arguments.
 func (t Tool) Execute(argsJSON string, ctx ContextVariables) (any, error) {
+	return t.ExecuteContext(context.Background(), argsJSON, ctx)
+}
+
+// ExecuteContext runs the tool's callback with the provided arguments and run context.
+func (t Tool) ExecuteContext(ctx context.Context, argsJSON string, vars ContextVariables) (any, error) {
 	// Handle empty args - default to empty JSON object
 	if argsJSON == "" {
 		argsJSON = "{}"
 	}
 
 	var args map[string]any
-	if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+	if err := t.decodeArgs(argsJSON, &args); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
 	}
+	if t.ArgumentCoercion != nil {
+		if args == nil {
+			args = map[string]any{}
+		}
+		t.ArgumentCoercion.apply(args, t.Parameters, t.UseNumber)
+	}
 
 	// Validate callback exists
+	if t.CallbackWithContext != nil {
+		return t.CallbackWithContext(ctx, args, vars)
+	}
 	if t.Callback == nil {
 		return nil, fmt.Errorf("tool %s has no callback function", t.Name)
 	}
 
-	return t.Callback(args, ctx)
+	return t.Callback(args, vars)
+}
+
+// executeWithRetry runs the tool, retrying failed calls as configured by
+// MaxRetries and RetryBackoff. It returns the number of attempts made.
+func (t Tool) executeWithRetry(ctx context.Context, argsJSON string, vars ContextVariables) (any, int, error) {
+	backoff := t.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := t.executeWithTimeout(ctx, argsJSON, vars)
+		if err == nil || attempt > t.MaxRetries {
+			return result, attempt, err
+		}
+
+		LoggerFromContext(ctx).Debug("retrying tool", "tool", t.Name, "attempt", attempt, "error", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// executeWithTimeout runs the tool, giving up after Timeout if it is set.
+// The callback runs on a copy of vars, as parallel tool calls do, and its
+// changes are merged back only when it finishes in time.
+func (t Tool) executeWithTimeout(ctx context.Context, argsJSON string, vars ContextVariables) (any, error) {
+	if t.Timeout <= 0 {
+		return t.ExecuteContext(ctx, argsJSON, vars)
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	type outcome struct {
+		result any
+		err    error
+	}
+	snapshot := maps.Clone(vars)
+	copied := maps.Clone(snapshot)
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := t.ExecuteContext(toolCtx, argsJSON, copied)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-done:
+		mergeContextVariables(vars, snapshot, copied)
+		return o.result, o.err
+	case <-toolCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w after %s", ErrToolTimeout, t.Timeout)
+	}
+}
+
+// decodeArgs unmarshals the argument JSON, honoring UseNumber.
+func (t Tool) decodeArgs(argsJSON string, args *map[string]any) error {
+	if !t.UseNumber {
+		return json.Unmarshal([]byte(argsJSON), args)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(argsJSON))
+	dec.UseNumber()
+	if err := dec.Decode(args); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after arguments object")
+	}
+	return nil
 }
 
 // FunctionTool is a helper to create a Tool from a simpler definition.
-// For now, it accepts manual schema. In the future, we could use reflection.
+// It accepts a manual schema; FunctionToolTyped generates one from a struct.
 func FunctionTool(name, description string, params map[string]any, callback func(map[string]any, ContextVariables) (any, error)) Tool {
 	if name == "" {
 		panic("tool name cannot be empty")
@@ -78,8 +255,101 @@ func FunctionTool(name, description string, params map[string]any, callback func
 	}
 }
 
-// IsHandoff checks if the result is an Agent, indicating a handoff.
+// FunctionToolWithContext is like FunctionTool but the callback also receives
+// the run context.
+func FunctionToolWithContext(name, description string, params map[string]any, callback func(context.Context, map[string]any, ContextVariables) (any, error)) Tool {
+	if name == "" {
+		panic("tool name cannot be empty")
+	}
+	if callback == nil {
+		panic("tool callback cannot be nil")
+	}
+
+	return Tool{
+		Name:                name,
+		Description:         description,
+		Parameters:          params,
+		CallbackWithContext: callback,
+	}
+}
+
+// FunctionToolTyped creates a Tool whose parameter schema is generated from
+// the struct type T, and decodes the arguments into a T before the callback
+// runs. Arguments that do not fit T are reported to the model as a failed
+// call.
+//
+// Properties are named by their json tags, and fields are required unless
+// they are pointers or tagged omitempty. The jsonschema tag adds
+// comma-separated options such as description, enum, minimum and maximum:
+//
+//	type WeatherArgs struct {
+//		City string `json:"city" jsonschema:"description=City name"`
+//		Unit string `json:"unit,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+//	}
+//
+// It panics if T is not a struct or has fields that cannot be described,
+// such as interfaces or channels.
+func FunctionToolTyped[T any](name, description string, callback func(T, ContextVariables) (any, error)) Tool {
+	if callback == nil {
+		panic("tool callback cannot be nil")
+	}
+	schema, err := jsonschema.For[T]()
+	if err != nil {
+		panic(fmt.Sprintf("tool %s: cannot generate schema: %v", name, err))
+	}
+	if schema.Type != jsonschema.TypeObject {
+		panic(fmt.Sprintf("tool %s: parameters must be a struct, got %s", name, schema.Type))
+	}
+	params, err := schema.ToMap()
+	if err != nil {
+		panic(fmt.Sprintf("tool %s: %v", name, err))
+	}
+
+	tool := FunctionTool(name, description, params, func(args map[string]any, vars ContextVariables) (any, error) {
+		var typed T
+		data, err := json.Marshal(args)
+		if err == nil {
+			err = json.Unmarshal(data, &typed)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		return callback(typed, vars)
+	})
+	// Keep numbers exact until they are decoded into T
+	tool.UseNumber = true
+	return tool
+}
+
+// ToolResult lets a tool callback return additional conversation messages
+// alongside its output, e.g. a retrieval tool injecting documents as a
+// separate context message.
+type ToolResult struct {
+	// Output is sent back to the model as the tool message content.
+	Output any
+	// Messages are appended to the history after all tool messages of the turn.
+	Messages []openai.ChatCompletionMessageParamUnion
+}
+
+// asToolResult unwraps a ToolResult returned by a callback.
+func asToolResult(result any) (ToolResult, bool) {
+	switch v := result.(type) {
+	case ToolResult:
+		return v, true
+	case *ToolResult:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return ToolResult{}, false
+}
+
+// IsHandoff checks if the result is an Agent or a Handoff, indicating a
+// handoff, and returns the agent taking over.
 func IsHandoff(result any) (*Agent, bool) {
-	a, ok := result.(*Agent)
-	return a, ok
+	h, ok := asHandoff(result)
+	if !ok {
+		return nil, false
+	}
+	return h.Agent, true
 }
@@ -1,8 +1,13 @@
 package agents
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestFunctionToolCreation(t *testing.T) {
@@ -163,6 +168,54 @@ func TestToolExecuteNilCallback(t *testing.T) {
 	}
 }
 
+func TestToolExecuteContext(t *testing.T) {
+	type ctxKey struct{}
+
+	tool := FunctionToolWithContext("ctx_tool", "desc", nil,
+		func(ctx context.Context, args map[string]any, _ ContextVariables) (any, error) {
+			return fmt.Sprintf("%v:%v", ctx.Value(ctxKey{}), args["x"]), nil
+		})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	result, err := tool.ExecuteContext(ctx, `{"x": "y"}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "value:y" {
+		t.Errorf("expected value:y, got %v", result)
+	}
+}
+
+func TestToolExecuteUseNumber(t *testing.T) {
+	var got any
+	tool := Tool{
+		Name: "count_tool",
+		Callback: func(args map[string]any, _ ContextVariables) (any, error) {
+			got = args["count"]
+			return ArgInt(args, "count")
+		},
+	}
+
+	if _, err := tool.Execute(`{"count": 9007199254740993}`, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := got.(float64); !ok {
+		t.Errorf("expected float64 without UseNumber, got %T", got)
+	}
+
+	tool.UseNumber = true
+	result, err := tool.Execute(`{"count": 9007199254740993}`, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := got.(json.Number); !ok {
+		t.Errorf("expected json.Number with UseNumber, got %T", got)
+	}
+	if result != 9007199254740993 {
+		t.Errorf("expected exact integer, got %v", result)
+	}
+}
+
 func TestIsHandoff(t *testing.T) {
 	agent := NewAgent("SupportAgent")
 
@@ -214,3 +267,263 @@ func TestToParam(t *testing.T) {
 		t.Error("failed to create param with nil parameters")
 	}
 }
+
+func TestToParam_Examples(t *testing.T) {
+	base := FunctionTool("get_weather", "Get the weather for a city", nil,
+		func(_ map[string]any, _ ContextVariables) (any, error) { return nil, nil })
+
+	tests := []struct {
+		name string
+		tool Tool
+		want string
+	}{
+		{
+			name: "no examples",
+			tool: base,
+			want: "Get the weather for a city",
+		},
+		{
+			name: "with result",
+			tool: base.WithExamples(ToolExample{Arguments: map[string]any{"city": "Tokyo"}, Result: "Sunny, 22C"}),
+			want: "Get the weather for a city\n\nExamples:\n- get_weather({\"city\":\"Tokyo\"}) -> Sunny, 22C",
+		},
+		{
+			name: "multiple without result",
+			tool: base.WithExamples(
+				ToolExample{Arguments: map[string]any{"city": "Paris"}},
+				ToolExample{},
+			),
+			want: "Get the weather for a city\n\nExamples:\n- get_weather({\"city\":\"Paris\"})\n- get_weather({})",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.tool.ToParam().Function.Description.Value
+			if got != tt.want {
+				t.Errorf("got description %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if len(base.Examples) != 0 {
+		t.Error("WithExamples must not modify the original tool")
+	}
+}
+
+func TestToolExecuteWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		maxRetries   int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "no retries configured", failures: 1, maxRetries: 0, wantAttempts: 1, wantErr: true},
+		{name: "succeeds after retries", failures: 2, maxRetries: 3, wantAttempts: 3, wantErr: false},
+		{name: "retries exhausted", failures: 5, maxRetries: 2, wantAttempts: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tool := FunctionTool("flaky", "Flaky", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, errors.New("unavailable")
+				}
+				return "ok", nil
+			})
+			tool.MaxRetries = tt.maxRetries
+			tool.RetryBackoff = time.Millisecond
+
+			result, attempts, err := tool.executeWithRetry(context.Background(), "{}", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d (callback ran %d times)", tt.wantAttempts, attempts, calls)
+			}
+			if !tt.wantErr && result != "ok" {
+				t.Errorf("expected result ok, got %v", result)
+			}
+		})
+	}
+}
+
+func TestToolExecuteWithRetry_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	tool := FunctionTool("flaky", "Flaky", nil, func(_ map[string]any, _ ContextVariables) (any, error) {
+		calls++
+		cancel()
+		return nil, errors.New("unavailable")
+	})
+	tool.MaxRetries = 5
+	tool.RetryBackoff = time.Hour
+
+	_, attempts, err := tool.executeWithRetry(ctx, "{}", nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 1 || calls != 1 {
+		t.Errorf("expected retries to stop on cancellation, got %d attempts", attempts)
+	}
+}
+
+func TestToolExecute_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	tests := []struct {
+		name     string
+		callback func(context.Context, map[string]any, ContextVariables) (any, error)
+		wantErr  error
+	}{
+		{
+			name: "finishes in time",
+			callback: func(context.Context, map[string]any, ContextVariables) (any, error) {
+				return "ok", nil
+			},
+		},
+		{
+			name: "honors context",
+			callback: func(ctx context.Context, _ map[string]any, _ ContextVariables) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			wantErr: ErrToolTimeout,
+		},
+		{
+			name: "ignores context",
+			callback: func(context.Context, map[string]any, ContextVariables) (any, error) {
+				<-release
+				return "late", nil
+			},
+			wantErr: ErrToolTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := FunctionToolWithContext("slow", "Slow", nil, tt.callback)
+			tool.Timeout = 10 * time.Millisecond
+
+			result, _, err := tool.executeWithRetry(context.Background(), "{}", nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && result != "ok" {
+				t.Errorf("expected result ok, got %v", result)
+			}
+		})
+	}
+}
+
+func TestToolExecute_TimeoutIsolatesContextVariables(t *testing.T) {
+	timedOut := make(chan struct{})
+	finished := make(chan struct{})
+	tool := FunctionTool("slow", "Slow", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+		vars["started"] = true
+		<-timedOut
+		// Keeps writing after the call timed out, ignoring its context
+		for i := range 100 {
+			vars[fmt.Sprintf("late_%d", i)] = i
+		}
+		close(finished)
+		return "late", nil
+	})
+	tool.Timeout = 10 * time.Millisecond
+
+	vars := ContextVariables{"user": "alice"}
+	_, _, err := tool.executeWithRetry(context.Background(), "{}", vars)
+	if !errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("expected ErrToolTimeout, got %v", err)
+	}
+
+	// The run carries on with the variables while the callback still runs
+	close(timedOut)
+	for i := range 100 {
+		vars[fmt.Sprintf("run_%d", i)] = i
+	}
+	<-finished
+
+	if _, ok := vars["started"]; ok {
+		t.Error("expected changes of a timed-out call to be discarded")
+	}
+	if _, ok := vars["late_0"]; ok {
+		t.Error("expected writes after the timeout not to reach the run's variables")
+	}
+	if vars["user"] != "alice" {
+		t.Errorf("expected existing variables to be kept, got %v", vars)
+	}
+}
+
+func TestToolExecute_TimeoutMergesContextVariables(t *testing.T) {
+	tool := FunctionTool("fast", "Fast", nil, func(_ map[string]any, vars ContextVariables) (any, error) {
+		vars["done"] = true
+		delete(vars, "stale")
+		return "ok", nil
+	})
+	tool.Timeout = time.Second
+
+	vars := ContextVariables{"stale": 1}
+	if _, _, err := tool.executeWithRetry(context.Background(), "{}", vars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vars["done"] != true {
+		t.Error("expected the callback's changes to be merged back")
+	}
+	if _, ok := vars["stale"]; ok {
+		t.Error("expected the callback's deletion to be merged back")
+	}
+}
+
+func TestFunctionToolTyped(t *testing.T) {
+	type weatherArgs struct {
+		City  string `json:"city" jsonschema:"description=City name"`
+		Days  int64  `json:"days"`
+		Units string `json:"units,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+	}
+
+	var got weatherArgs
+	tool := FunctionToolTyped("get_weather", "Get the forecast", func(args weatherArgs, _ ContextVariables) (any, error) {
+		got = args
+		return "sunny", nil
+	})
+
+	wantParams := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"city":  map[string]any{"type": "string", "description": "City name"},
+			"days":  map[string]any{"type": "integer"},
+			"units": map[string]any{"type": "string", "enum": []any{"celsius", "fahrenheit"}},
+		},
+		"required":             []any{"city", "days"},
+		"additionalProperties": false,
+	}
+	if !reflect.DeepEqual(tool.Parameters, wantParams) {
+		t.Errorf("unexpected parameters %v", tool.Parameters)
+	}
+
+	result, err := tool.Execute(`{"city": "Paris", "days": 9007199254740993}`, nil)
+	if err != nil || result != "sunny" {
+		t.Fatalf("unexpected result %v, %v", result, err)
+	}
+	if got != (weatherArgs{City: "Paris", Days: 9007199254740993}) {
+		t.Errorf("unexpected decoded args %+v", got)
+	}
+
+	if _, err := tool.Execute(`{"city": 42}`, nil); err == nil {
+		t.Error("expected error for arguments that do not fit the struct")
+	}
+}
+
+func TestFunctionToolTyped_PanicsOnNonStruct(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	FunctionToolTyped("bad", "", func(string, ContextVariables) (any, error) { return nil, nil })
+}
@@ -0,0 +1,483 @@
+// Package tools generates agent tools from external API descriptions.
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+// bodyArg is the tool argument that carries the JSON request body
+const bodyArg = "body"
+
+// maxResponseBytes bounds how much of a response body is returned to the model
+const maxResponseBytes = 1 << 20
+
+// httpMethods lists the path item keys that describe operations, in the
+// order tools are generated for a path.
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// invalidNameChars matches characters OpenAI does not accept in tool names.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Operation describes an API operation, as passed to an operation filter.
+type Operation struct {
+	// Method is the upper-case HTTP method
+	Method string
+
+	// Path is the path template, e.g. "/pets/{petId}"
+	Path string
+
+	// ID is the operationId, or "" if the spec does not define one
+	ID string
+
+	// Tags are the operation's tags
+	Tags []string
+}
+
+// Option configures FromOpenAPI.
+type Option func(*options)
+
+type options struct {
+	client  *http.Client
+	editors []func(*http.Request) error
+	filter  func(Operation) bool
+}
+
+// WithHTTPClient sets the client used for API calls (default: http.DefaultClient).
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithRequestEditor registers a function that can modify every request
+// before it is sent, e.g. to inject authentication. Returning an error
+// fails the tool call.
+func WithRequestEditor(edit func(*http.Request) error) Option {
+	return func(o *options) {
+		o.editors = append(o.editors, edit)
+	}
+}
+
+// WithBearerToken authenticates every request with the given bearer token.
+func WithBearerToken(token string) Option {
+	return WithRequestEditor(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// WithHeader sets a header on every request, e.g. an API key.
+func WithHeader(key, value string) Option {
+	return WithRequestEditor(func(req *http.Request) error {
+		req.Header.Set(key, value)
+		return nil
+	})
+}
+
+// WithOperationFilter only generates tools for operations the filter accepts.
+func WithOperationFilter(filter func(Operation) bool) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithOperations only generates tools for the operations with the given IDs.
+func WithOperations(ids ...string) Option {
+	allowed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		allowed[id] = true
+	}
+	return WithOperationFilter(func(op Operation) bool {
+		return allowed[op.ID]
+	})
+}
+
+// openAPIDoc is the subset of an OpenAPI 3 document used to build tools.
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas       map[string]any         `json:"schemas"`
+		Parameters    map[string]parameter   `json:"parameters"`
+		RequestBodies map[string]requestBody `json:"requestBodies"`
+	} `json:"components"`
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description"`
+	Tags        []string     `json:"tags"`
+	Parameters  []parameter  `json:"parameters"`
+	RequestBody *requestBody `json:"requestBody"`
+}
+
+type parameter struct {
+	Ref         string         `json:"$ref"`
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description"`
+	Required    bool           `json:"required"`
+	Schema      map[string]any `json:"schema"`
+}
+
+type requestBody struct {
+	Ref         string `json:"$ref"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	Content     map[string]struct {
+		Schema map[string]any `json:"schema"`
+	} `json:"content"`
+}
+
+// FromOpenAPI generates one tool per operation of a JSON-encoded OpenAPI 3
+// document. Each tool's parameters are derived from the operation's path,
+// query and header parameters, plus a "body" argument for JSON request
+// bodies, and its callback calls the operation on baseURL and returns the
+// response body. Responses with a status of 400 or above are reported as
+// errors.
+//
+// Tools are named after the operationId, or after the method and path when
+// it is missing. Local $ref references into components are resolved;
+// external references are not supported.
+func FromOpenAPI(spec []byte, baseURL string, opts ...Option) ([]agents.Tool, error) {
+	o := &options{client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var doc openAPIDoc
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var tools []agents.Tool
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		item := doc.Paths[path]
+
+		var shared []parameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("invalid parameters for %s: %w", path, err)
+			}
+		}
+
+		for _, method := range httpMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			info := Operation{Method: strings.ToUpper(method), Path: path, ID: op.OperationID, Tags: op.Tags}
+			if o.filter != nil && !o.filter(info) {
+				continue
+			}
+
+			tool, err := doc.buildTool(info, op, shared, baseURL, o)
+			if err != nil {
+				return nil, err
+			}
+			if seen[tool.Name] {
+				return nil, fmt.Errorf("duplicate tool name %q for %s %s", tool.Name, info.Method, path)
+			}
+			seen[tool.Name] = true
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+// buildTool creates the tool for a single operation.
+func (d *openAPIDoc) buildTool(info Operation, op operation, shared []parameter, baseURL string, o *options) (agents.Tool, error) {
+	params, err := d.mergeParameters(shared, op.Parameters)
+	if err != nil {
+		return agents.Tool{}, fmt.Errorf("%s %s: %w", info.Method, info.Path, err)
+	}
+
+	properties := make(map[string]any, len(params)+1)
+	var required []string
+	for _, p := range params {
+		schema := d.resolveSchema(p.Schema, nil)
+		if schema == nil {
+			schema = map[string]any{"type": "string"}
+		}
+		if p.Description != "" {
+			schema["description"] = p.Description
+		}
+		properties[p.Name] = schema
+		if p.Required || p.In == "path" {
+			required = append(required, p.Name)
+		}
+	}
+
+	var hasBody bool
+	if op.RequestBody != nil {
+		body, err := d.resolveRequestBody(*op.RequestBody)
+		if err != nil {
+			return agents.Tool{}, fmt.Errorf("%s %s: %w", info.Method, info.Path, err)
+		}
+		if media, ok := body.Content["application/json"]; ok {
+			hasBody = true
+			schema := d.resolveSchema(media.Schema, nil)
+			if schema == nil {
+				schema = map[string]any{"type": "object"}
+			}
+			if body.Description != "" {
+				schema["description"] = body.Description
+			}
+			properties[bodyArg] = schema
+			if body.Required {
+				required = append(required, bodyArg)
+			}
+		}
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	description := op.Summary
+	if op.Description != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += op.Description
+	}
+
+	call := &operationCall{
+		method:  info.Method,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		path:    info.Path,
+		params:  params,
+		hasBody: hasBody,
+		opts:    o,
+	}
+	return agents.FunctionToolWithContext(toolName(info), description, schema, call.do), nil
+}
+
+// mergeParameters resolves parameter references and lets operation
+// parameters override path-level ones with the same name and location.
+func (d *openAPIDoc) mergeParameters(shared, own []parameter) ([]parameter, error) {
+	var merged []parameter
+	index := make(map[string]int)
+	for _, list := range [][]parameter{shared, own} {
+		for _, p := range list {
+			p, err := d.resolveParameter(p)
+			if err != nil {
+				return nil, err
+			}
+			if p.In == "cookie" {
+				continue
+			}
+			key := p.In + ":" + p.Name
+			if i, ok := index[key]; ok {
+				merged[i] = p
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, p)
+		}
+	}
+	return merged, nil
+}
+
+func (d *openAPIDoc) resolveParameter(p parameter) (parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	resolved, found := d.Components.Parameters[name]
+	if !ok || !found {
+		return p, fmt.Errorf("unresolved parameter reference %q", p.Ref)
+	}
+	return resolved, nil
+}
+
+func (d *openAPIDoc) resolveRequestBody(b requestBody) (requestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	name, ok := strings.CutPrefix(b.Ref, "#/components/requestBodies/")
+	resolved, found := d.Components.RequestBodies[name]
+	if !ok || !found {
+		return b, fmt.Errorf("unresolved request body reference %q", b.Ref)
+	}
+	return resolved, nil
+}
+
+// resolveSchema returns a copy of schema with local schema references
+// inlined. Recursive references are replaced with an untyped object.
+func (d *openAPIDoc) resolveSchema(schema map[string]any, visiting map[string]bool) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+		target, found := d.Components.Schemas[name].(map[string]any)
+		if !ok || !found || visiting[name] {
+			return map[string]any{"type": "object"}
+		}
+		next := make(map[string]bool, len(visiting)+1)
+		for k := range visiting {
+			next[k] = true
+		}
+		next[name] = true
+		return d.resolveSchema(target, next)
+	}
+
+	resolved := make(map[string]any, len(schema))
+	for k, v := range schema {
+		resolved[k] = d.resolveValue(v, visiting)
+	}
+	return resolved
+}
+
+func (d *openAPIDoc) resolveValue(v any, visiting map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return d.resolveSchema(v, visiting)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = d.resolveValue(item, visiting)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// toolName derives a valid tool name from the operation.
+func toolName(op Operation) string {
+	name := op.ID
+	if name == "" {
+		name = strings.ToLower(op.Method) + "_" + strings.Trim(op.Path, "/")
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// operationCall performs the HTTP request behind a generated tool.
+type operationCall struct {
+	method  string
+	baseURL string
+	path    string
+	params  []parameter
+	hasBody bool
+	opts    *options
+}
+
+func (c *operationCall) do(ctx context.Context, args map[string]any, _ agents.ContextVariables) (any, error) {
+	path := c.path
+	query := url.Values{}
+	header := http.Header{}
+	for _, p := range c.params {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.In == "path" {
+				return nil, fmt.Errorf("missing path parameter %q", p.Name)
+			}
+			continue
+		}
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(paramString(v)))
+		case "query":
+			if items, ok := v.([]any); ok {
+				for _, item := range items {
+					query.Add(p.Name, paramString(item))
+				}
+			} else {
+				query.Set(p.Name, paramString(v))
+			}
+		case "header":
+			header.Set(p.Name, paramString(v))
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if v, ok := args[bodyArg]; ok && c.hasBody && v != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+		header.Set("Content-Type", "application/json")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	for _, edit := range c.opts.editors {
+		if err := edit(req); err != nil {
+			return nil, fmt.Errorf("failed to prepare request: %w", err)
+		}
+	}
+
+	resp, err := c.opts.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", c.method, c.path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("%s %s returned %s: %s", c.method, c.path, resp.Status, data)
+	}
+	return string(data), nil
+}
+
+// paramString formats a parameter value for a URL or header.
+func paramString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	agents "github.com/MitulShah1/openai-agents-go"
+)
+
+const petStoreSpec = `{
+	"openapi": "3.0.0",
+	"paths": {
+		"/pets": {
+			"get": {
+				"operationId": "listPets",
+				"summary": "List pets",
+				"tags": ["read"],
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer"}},
+					{"$ref": "#/components/parameters/Tenant"}
+				]
+			},
+			"post": {
+				"operationId": "createPet",
+				"summary": "Create a pet",
+				"requestBody": {
+					"required": true,
+					"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+				}
+			}
+		},
+		"/pets/{petId}": {
+			"parameters": [
+				{"name": "petId", "in": "path", "description": "The pet ID", "schema": {"type": "string"}}
+			],
+			"delete": {"tags": ["write"]}
+		}
+	},
+	"components": {
+		"parameters": {
+			"Tenant": {"name": "X-Tenant", "in": "header", "schema": {"type": "string"}}
+		},
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"parent": {"$ref": "#/components/schemas/Pet"}
+				},
+				"required": ["name"]
+			}
+		}
+	}
+}`
+
+// recordedRequest captures what the test API server received.
+type recordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+}
+
+func newTestAPI(t *testing.T, status int) (string, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{
+			Method: r.Method,
+			URL:    r.URL.String(),
+			Header: r.Header,
+			Body:   string(body),
+		})
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"ok":true}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &requests
+}
+
+func toolsByName(t *testing.T, tools []agents.Tool) map[string]agents.Tool {
+	t.Helper()
+	byName := make(map[string]agents.Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+	return byName
+}
+
+func TestFromOpenAPI_Schemas(t *testing.T) {
+	tools, err := FromOpenAPI([]byte(petStoreSpec), "http://example.com")
+	if err != nil {
+		t.Fatalf("FromOpenAPI failed: %v", err)
+	}
+	byName := toolsByName(t, tools)
+	if len(byName) != 3 {
+		t.Fatalf("expected 3 tools, got %v", byName)
+	}
+
+	list := byName["listPets"]
+	if list.Description != "List pets" {
+		t.Errorf("expected summary as description, got %q", list.Description)
+	}
+	props := list.Parameters["properties"].(map[string]any)
+	if _, ok := props["X-Tenant"]; !ok {
+		t.Errorf("expected referenced header parameter, got %v", props)
+	}
+
+	create := byName["createPet"]
+	if !reflect.DeepEqual(create.Parameters["required"], []string{"body"}) {
+		t.Errorf("expected required body, got %v", create.Parameters["required"])
+	}
+	body := create.Parameters["properties"].(map[string]any)["body"].(map[string]any)
+	parent := body["properties"].(map[string]any)["parent"].(map[string]any)
+	if parent["type"] != "object" || parent["properties"] != nil {
+		t.Errorf("expected recursive reference to be cut off, got %v", parent)
+	}
+
+	del, ok := byName["delete_pets_petId"]
+	if !ok {
+		t.Fatalf("expected tool named after method and path, got %v", byName)
+	}
+	petID := del.Parameters["properties"].(map[string]any)["petId"].(map[string]any)
+	if petID["description"] != "The pet ID" {
+		t.Errorf("expected path-level parameter with description, got %v", petID)
+	}
+	if !reflect.DeepEqual(del.Parameters["required"], []string{"petId"}) {
+		t.Errorf("expected path parameter to be required, got %v", del.Parameters["required"])
+	}
+}
+
+func TestFromOpenAPI_Calls(t *testing.T) {
+	baseURL, requests := newTestAPI(t, http.StatusOK)
+	tools, err := FromOpenAPI([]byte(petStoreSpec), baseURL+"/", WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("FromOpenAPI failed: %v", err)
+	}
+	byName := toolsByName(t, tools)
+
+	result, err := byName["listPets"].Execute(`{"limit": 10, "X-Tenant": "acme"}`, nil)
+	if err != nil {
+		t.Fatalf("listPets failed: %v", err)
+	}
+	if result != `{"ok":true}` {
+		t.Errorf("expected response body, got %v", result)
+	}
+	if _, err := byName["createPet"].Execute(`{"body": {"name": "Rex"}}`, nil); err != nil {
+		t.Fatalf("createPet failed: %v", err)
+	}
+	if _, err := byName["delete_pets_petId"].Execute(`{"petId": "a b"}`, nil); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	got := *requests
+	if len(got) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(got))
+	}
+	if got[0].Method != http.MethodGet || got[0].URL != "/pets?limit=10" {
+		t.Errorf("unexpected list request %s %s", got[0].Method, got[0].URL)
+	}
+	if got[0].Header.Get("X-Tenant") != "acme" || got[0].Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected tenant and auth headers, got %v", got[0].Header)
+	}
+	if got[1].Method != http.MethodPost || got[1].Body != `{"name":"Rex"}` {
+		t.Errorf("unexpected create request %s %s", got[1].Method, got[1].Body)
+	}
+	if got[2].Method != http.MethodDelete || got[2].URL != "/pets/a%20b" {
+		t.Errorf("unexpected delete request %s %s", got[2].Method, got[2].URL)
+	}
+}
+
+func TestFromOpenAPI_ErrorStatus(t *testing.T) {
+	baseURL, _ := newTestAPI(t, http.StatusNotFound)
+	tools, err := FromOpenAPI([]byte(petStoreSpec), baseURL, WithOperations("listPets"))
+	if err != nil {
+		t.Fatalf("FromOpenAPI failed: %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("expected only the selected operation, got %d tools", len(tools))
+	}
+
+	_, err = tools[0].Execute(`{}`, nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected error with status, got %v", err)
+	}
+}
+
+func TestFromOpenAPI_Filter(t *testing.T) {
+	tools, err := FromOpenAPI([]byte(petStoreSpec), "http://example.com",
+		WithOperationFilter(func(op Operation) bool {
+			return op.Method == http.MethodGet || len(op.Tags) > 0 && op.Tags[0] == "write"
+		}))
+	if err != nil {
+		t.Fatalf("FromOpenAPI failed: %v", err)
+	}
+
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	if want := []string{"listPets", "delete_pets_petId"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
+func TestFromOpenAPI_InvalidSpec(t *testing.T) {
+	if _, err := FromOpenAPI([]byte(`not json`), "http://example.com"); err == nil {
+		t.Error("expected error for invalid document")
+	}
+
+	spec, _ := json.Marshal(map[string]any{
+		"paths": map[string]any{
+			"/a": map[string]any{"get": map[string]any{
+				"parameters": []any{map[string]any{"$ref": "#/components/parameters/Missing"}},
+			}},
+		},
+	})
+	if _, err := FromOpenAPI(spec, "http://example.com"); err == nil {
+		t.Error("expected error for unresolved reference")
+	}
+}
@@ -0,0 +1,175 @@
+package agents
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// TranscriptFormat selects the output of RenderTranscript.
+type TranscriptFormat string
+
+const (
+	// TranscriptMarkdown renders a transcript as Markdown
+	TranscriptMarkdown TranscriptFormat = "markdown"
+
+	// TranscriptHTML renders a transcript as an HTML fragment
+	TranscriptHTML TranscriptFormat = "html"
+)
+
+// transcriptEntry is one message of a transcript in a renderable form.
+type transcriptEntry struct {
+	title     string
+	role      string
+	text      string
+	toolCalls []openai.ChatCompletionMessageToolCallFunctionParam
+	result    bool
+}
+
+// RenderTranscript formats messages, such as Result.Messages or a
+// Conversation's history, as a readable transcript for bug reports and
+// support tickets. Tool calls are shown with their arguments and tool
+// results are labelled with the tool that produced them. Non-text content
+// such as images is noted by type.
+func RenderTranscript(messages []openai.ChatCompletionMessageParamUnion, format TranscriptFormat) (string, error) {
+	entries := transcriptEntries(messages)
+	switch format {
+	case TranscriptMarkdown:
+		return renderMarkdown(entries), nil
+	case TranscriptHTML:
+		return renderHTML(entries), nil
+	default:
+		return "", fmt.Errorf("unknown transcript format %q", format)
+	}
+}
+
+func transcriptEntries(messages []openai.ChatCompletionMessageParamUnion) []transcriptEntry {
+	toolNames := make(map[string]string)
+	entries := make([]transcriptEntry, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.OfSystem != nil:
+			c := msg.OfSystem.Content
+			entries = append(entries, transcriptEntry{title: "System", role: "system", text: textOrParts(c.OfString, c.OfArrayOfContentParts)})
+		case msg.OfDeveloper != nil:
+			c := msg.OfDeveloper.Content
+			entries = append(entries, transcriptEntry{title: "Developer", role: "developer", text: textOrParts(c.OfString, c.OfArrayOfContentParts)})
+		case msg.OfUser != nil:
+			entries = append(entries, transcriptEntry{title: "User", role: "user", text: userText(msg.OfUser, true)})
+		case msg.OfAssistant != nil:
+			a := msg.OfAssistant
+			entry := transcriptEntry{title: "Assistant", role: "assistant", text: assistantText(a)}
+			for _, call := range a.ToolCalls {
+				toolNames[call.ID] = call.Function.Name
+				entry.toolCalls = append(entry.toolCalls, call.Function)
+			}
+			entries = append(entries, entry)
+		case msg.OfTool != nil:
+			c := msg.OfTool.Content
+			title := "Tool result"
+			if name := toolNames[msg.OfTool.ToolCallID]; name != "" {
+				title += ": " + name
+			}
+			entries = append(entries, transcriptEntry{title: title, role: "tool", text: textOrParts(c.OfString, c.OfArrayOfContentParts), result: true})
+		case msg.OfFunction != nil:
+			entries = append(entries, transcriptEntry{title: "Function result: " + msg.OfFunction.Name, role: "tool", text: msg.OfFunction.Content.Value, result: true})
+		}
+	}
+	return entries
+}
+
+// textOrParts returns the text of a message content given as a string or
+// as text parts.
+func textOrParts(s param.Opt[string], parts []openai.ChatCompletionContentPartTextParam) string {
+	if s.Valid() {
+		return s.Value
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		texts = append(texts, part.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func assistantText(a *openai.ChatCompletionAssistantMessageParam) string {
+	if a.Content.OfString.Valid() {
+		return a.Content.OfString.Value
+	}
+	var texts []string
+	for _, part := range a.Content.OfArrayOfContentParts {
+		switch {
+		case part.OfText != nil:
+			texts = append(texts, part.OfText.Text)
+		case part.OfRefusal != nil:
+			texts = append(texts, "Refused: "+part.OfRefusal.Refusal)
+		}
+	}
+	if a.Refusal.Valid() {
+		texts = append(texts, "Refused: "+a.Refusal.Value)
+	}
+	return strings.Join(texts, "\n")
+}
+
+func renderMarkdown(entries []transcriptEntry) string {
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s\n", e.title)
+		if e.text != "" {
+			b.WriteString("\n")
+			if e.result {
+				writeFence(&b, "", e.text)
+			} else {
+				b.WriteString(e.text + "\n")
+			}
+		}
+		for _, call := range e.toolCalls {
+			fmt.Fprintf(&b, "\nTool call: `%s`\n\n", call.Name)
+			writeFence(&b, "json", call.Arguments)
+		}
+	}
+	return b.String()
+}
+
+// writeFence writes text as a fenced code block, using a fence longer than
+// any backtick run in the text so it cannot close the block early.
+func writeFence(b *strings.Builder, lang, text string) {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(text, "\n"), fence)
+}
+
+func renderHTML(entries []transcriptEntry) string {
+	var b strings.Builder
+	b.WriteString("<div class=\"transcript\">\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "<div class=\"message %s\">\n<h3>%s</h3>\n", e.role, html.EscapeString(e.title))
+		if e.text != "" {
+			if e.result {
+				fmt.Fprintf(&b, "<pre>%s</pre>\n", html.EscapeString(e.text))
+			} else {
+				fmt.Fprintf(&b, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(e.text), "\n", "<br>\n"))
+			}
+		}
+		for _, call := range e.toolCalls {
+			fmt.Fprintf(&b, "<p>Tool call: <code>%s</code></p>\n<pre>%s</pre>\n",
+				html.EscapeString(call.Name), html.EscapeString(call.Arguments))
+		}
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</div>\n")
+	return b.String()
+}
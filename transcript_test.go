@@ -0,0 +1,64 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func transcriptMessages() []openai.ChatCompletionMessageParamUnion {
+	assistant := openai.ChatCompletionAssistantMessageParam{
+		ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+			ID:       "call_1",
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "lookup", Arguments: `{"q":"<go>"}`},
+		}},
+	}
+	return []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("Be brief."),
+		openai.UserMessage("Find Go"),
+		{OfAssistant: &assistant},
+		openai.ToolMessage("found ```code```", "call_1"),
+		openai.AssistantMessage("Go & more"),
+	}
+}
+
+func TestRenderTranscript_Markdown(t *testing.T) {
+	got, err := RenderTranscript(transcriptMessages(), TranscriptMarkdown)
+	if err != nil {
+		t.Fatalf("RenderTranscript failed: %v", err)
+	}
+
+	want := "### System\n\nBe brief.\n" +
+		"\n### User\n\nFind Go\n" +
+		"\n### Assistant\n\nTool call: `lookup`\n\n```json\n{\"q\":\"<go>\"}\n```\n" +
+		"\n### Tool result: lookup\n\n````\nfound ```code```\n````\n" +
+		"\n### Assistant\n\nGo & more\n"
+	if got != want {
+		t.Errorf("unexpected transcript:\n%s", got)
+	}
+}
+
+func TestRenderTranscript_HTML(t *testing.T) {
+	got, err := RenderTranscript(transcriptMessages(), TranscriptHTML)
+	if err != nil {
+		t.Fatalf("RenderTranscript failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`<div class="message user">`,
+		`<h3>Tool result: lookup</h3>`,
+		`<pre>{&#34;q&#34;:&#34;&lt;go&gt;&#34;}</pre>`,
+		`<p>Go &amp; more</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in transcript:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderTranscript_UnknownFormat(t *testing.T) {
+	if _, err := RenderTranscript(transcriptMessages(), "pdf"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
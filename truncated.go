@@ -0,0 +1,119 @@
+package agents
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// finishReasonLength is the finish reason of a completion cut off by the token limit.
+const finishReasonLength = "length"
+
+// jsonFrame is an open object or array while scanning truncated JSON.
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+}
+
+// repairTruncatedJSON makes a best effort to turn JSON that was cut off
+// mid-document into valid JSON. An unterminated string value is closed,
+// incomplete trailing keys, numbers and literals are dropped, and open
+// objects and arrays are closed. It returns false if nothing valid can be
+// recovered.
+func repairTruncatedJSON(s string) (string, bool) {
+	var stack []jsonFrame
+	cut := -1
+	var cutStack []jsonFrame
+	mark := func(pos int) {
+		cut = pos
+		cutStack = append(cutStack[:0], stack...)
+	}
+	top := func() *jsonFrame {
+		if len(stack) == 0 {
+			return nil
+		}
+		return &stack[len(stack)-1]
+	}
+
+	inString, escaped, isKey := false, false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				if !isKey {
+					mark(i + 1)
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '{':
+			stack = append(stack, jsonFrame{object: true, expectKey: true})
+			mark(i + 1)
+		case '[':
+			stack = append(stack, jsonFrame{})
+			mark(i + 1)
+		case '}', ']':
+			if len(stack) == 0 {
+				return "", false
+			}
+			stack = stack[:len(stack)-1]
+			mark(i + 1)
+		case ',', ':':
+			if f := top(); f != nil && f.object {
+				f.expectKey = c == ','
+			}
+		case '"':
+			inString = true
+			f := top()
+			isKey = f != nil && f.object && f.expectKey
+		case ' ', '\t', '\n', '\r':
+		default:
+			// A number or literal only counts once a delimiter proves it complete
+			j := i
+			for j < len(s) && !strings.ContainsRune(",}] \t\n\r", rune(s[j])) {
+				j++
+			}
+			if j < len(s) {
+				mark(j)
+			}
+			i = j - 1
+		}
+	}
+
+	// Keep a partial string value by closing it, if that yields valid JSON
+	if inString && !isKey {
+		partial := s
+		if escaped {
+			partial = partial[:len(partial)-1]
+		}
+		if repaired := partial + `"` + closeJSONFrames(stack); json.Valid([]byte(repaired)) {
+			return repaired, true
+		}
+	}
+
+	if cut < 0 {
+		return "", false
+	}
+	repaired := s[:cut] + closeJSONFrames(cutStack)
+	return repaired, json.Valid([]byte(repaired))
+}
+
+// closeJSONFrames returns the brackets closing the open frames, innermost first.
+func closeJSONFrames(stack []jsonFrame) string {
+	var b strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].object {
+			b.WriteByte('}')
+		} else {
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package agents
+
+import "testing"
+
+func TestRepairTruncatedJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{name: "complete", input: `{"a":1}`, want: `{"a":1}`, wantOK: true},
+		{name: "open object", input: `{"a":1,"b":[1,2`, want: `{"a":1,"b":[1]}`, wantOK: true},
+		{name: "partial string value", input: `{"title":"Hello wor`, want: `{"title":"Hello wor"}`, wantOK: true},
+		{name: "dangling key", input: `{"a":"x","b`, want: `{"a":"x"}`, wantOK: true},
+		{name: "dangling colon", input: `{"a":"x","b":`, want: `{"a":"x"}`, wantOK: true},
+		{name: "partial literal", input: `{"a":"x","ok":tr`, want: `{"a":"x"}`, wantOK: true},
+		{name: "trailing escape", input: `{"a":"line\`, want: `{"a":"line"}`, wantOK: true},
+		{name: "partial unicode escape", input: `{"a":"x","b":"caf\u00`, want: `{"a":"x"}`, wantOK: true},
+		{name: "nested arrays", input: `{"items":[{"n":1},{"n":2},{"n"`, want: `{"items":[{"n":1},{"n":2},{}]}`, wantOK: true},
+		{name: "string containing brackets", input: `{"a":"}]","b":[`, want: `{"a":"}]","b":[]}`, wantOK: true},
+		{name: "empty", input: ``, wantOK: false},
+		{name: "not json", input: `hello`, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := repairTruncatedJSON(tt.input)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v (%q)", tt.wantOK, ok, got)
+			}
+			if ok && got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// Turn is a message of a run in a structured form. Unlike the message
+// unions in Result.Messages, tool results are attached to the tool calls
+// that produced them, so an assistant turn carries everything that
+// happened in its step.
+type Turn struct {
+	// Role is "system", "developer", "user", "assistant" or, for tool
+	// messages that answer no known call, "tool"
+	Role string `json:"role"`
+
+	// Content is the text of the message; non-text parts are noted by type
+	Content string `json:"content,omitempty"`
+
+	// ToolCalls are the tools the assistant called in this turn
+	ToolCalls []TurnToolCall `json:"tool_calls,omitempty"`
+}
+
+// TurnToolCall is a tool call of an assistant turn with its result.
+type TurnToolCall struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Arguments are the decoded call arguments, or nil if they were not a
+	// JSON object
+	Arguments map[string]any `json:"arguments,omitempty"`
+
+	// Result is the value the tool returned during the run. For calls
+	// passed in with the history, it is the content of the tool message.
+	Result any `json:"result,omitempty"`
+
+	// Error is the message of the error the tool call failed with
+	Error string `json:"error,omitempty"`
+}
+
+// buildTurns converts history into turns, taking the results of tool calls
+// made during the run from steps.
+func buildTurns(history []openai.ChatCompletionMessageParamUnion, steps []Step) []Turn {
+	recorded := make(map[string]ToolCall)
+	for _, step := range steps {
+		for _, call := range step.ToolCalls {
+			recorded[call.ID] = call
+		}
+	}
+
+	turns := make([]Turn, 0, len(history))
+	// pending locates the tool call a tool message answers
+	pending := make(map[string]*TurnToolCall)
+	for _, msg := range history {
+		switch {
+		case msg.OfSystem != nil:
+			c := msg.OfSystem.Content
+			turns = append(turns, Turn{Role: "system", Content: textOrParts(c.OfString, c.OfArrayOfContentParts)})
+		case msg.OfDeveloper != nil:
+			c := msg.OfDeveloper.Content
+			turns = append(turns, Turn{Role: "developer", Content: textOrParts(c.OfString, c.OfArrayOfContentParts)})
+		case msg.OfUser != nil:
+			turns = append(turns, Turn{Role: "user", Content: userText(msg.OfUser, true)})
+		case msg.OfAssistant != nil:
+			turn := Turn{Role: "assistant", Content: assistantText(msg.OfAssistant)}
+			for _, call := range msg.OfAssistant.ToolCalls {
+				tc := TurnToolCall{ID: call.ID, Name: call.Function.Name}
+				_ = json.Unmarshal([]byte(call.Function.Arguments), &tc.Arguments)
+				if rec, ok := recorded[call.ID]; ok {
+					switch agent, isHandoff := IsHandoff(rec.Result); {
+					case rec.Error != nil:
+						tc.Error = rec.Error.Error()
+					case isHandoff:
+						tc.Result = fmt.Sprintf("Transferred to %s", agent.Name)
+					default:
+						tc.Result = rec.Result
+					}
+				}
+				turn.ToolCalls = append(turn.ToolCalls, tc)
+			}
+			turns = append(turns, turn)
+			for i := range turn.ToolCalls {
+				pending[turn.ToolCalls[i].ID] = &turns[len(turns)-1].ToolCalls[i]
+			}
+		case msg.OfTool != nil:
+			c := msg.OfTool.Content
+			content := textOrParts(c.OfString, c.OfArrayOfContentParts)
+			tc, ok := pending[msg.OfTool.ToolCallID]
+			if !ok {
+				turns = append(turns, Turn{Role: "tool", Content: content})
+				continue
+			}
+			if tc.Result == nil && tc.Error == "" {
+				tc.Result = content
+			}
+		}
+	}
+	return turns
+}
@@ -0,0 +1,75 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestRun_Turns(t *testing.T) {
+	runner, _ := newMockRunner(t,
+		toolCallCompletion(
+			mockToolCall{ID: "call_add", Name: "add", Arguments: `{"a": 1, "b": 2}`},
+			mockToolCall{ID: "call_fail", Name: "fail", Arguments: `{}`},
+		),
+		textCompletion("3"),
+	)
+
+	agent := NewAgent("Calculator")
+	agent.Instructions = "Add numbers."
+	agent.Tools = []Tool{
+		FunctionTool("add", "Adds", nil, func(args map[string]any, _ ContextVariables) (any, error) {
+			return map[string]any{"sum": args["a"].(float64) + args["b"].(float64)}, nil
+		}),
+		FunctionTool("fail", "Fails", nil, func(map[string]any, ContextVariables) (any, error) {
+			return nil, errors.New("boom")
+		}),
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage("1+2?")}
+	result, err := runner.Run(context.Background(), agent, messages, nil, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []Turn{
+		{Role: "user", Content: "1+2?"},
+		{Role: "assistant", ToolCalls: []TurnToolCall{
+			{ID: "call_add", Name: "add", Arguments: map[string]any{"a": 1.0, "b": 2.0}, Result: map[string]any{"sum": 3.0}},
+			{ID: "call_fail", Name: "fail", Arguments: map[string]any{}, Error: "tool fail failed: boom"},
+		}},
+		{Role: "assistant", Content: "3"},
+	}
+	if !reflect.DeepEqual(result.Turns, want) {
+		got, _ := json.Marshal(result.Turns)
+		t.Errorf("unexpected turns: %s", got)
+	}
+}
+
+func TestBuildTurns_HistoryToolMessages(t *testing.T) {
+	assistant := openai.ChatCompletionAssistantMessageParam{
+		ToolCalls: []openai.ChatCompletionMessageToolCallParam{{
+			ID:       "call_1",
+			Function: openai.ChatCompletionMessageToolCallFunctionParam{Name: "lookup", Arguments: `not json`},
+		}},
+	}
+	history := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("Be brief."),
+		{OfAssistant: &assistant},
+		openai.ToolMessage("found", "call_1"),
+		openai.ToolMessage("orphan", "call_2"),
+	}
+
+	want := []Turn{
+		{Role: "system", Content: "Be brief."},
+		{Role: "assistant", ToolCalls: []TurnToolCall{{ID: "call_1", Name: "lookup", Result: "found"}}},
+		{Role: "tool", Content: "orphan"},
+	}
+	if got := buildTurns(history, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected turns: %+v", got)
+	}
+}
@@ -1,16 +1,28 @@
 package agents
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
 // Result is the output of running an agent.
 type Result struct {
+	// RunID uniquely identifies the run; it matches RunIDFromContext
+	// inside tools and hooks.
+	RunID string
+
 	// Messages is the conversation history.
 	Messages []openai.ChatCompletionMessageParamUnion
 
+	// Turns is the conversation history in a structured form that is easier
+	// to iterate and serialize than Messages. See Turn.
+	Turns []Turn
+
 	// Agent is the final agent that handled the request.
 	Agent *Agent
 
@@ -20,20 +32,185 @@ type Result struct {
 	// Steps records the execution trace
 	Steps []Step
 
+	// ToolCallCount is the number of tool calls executed during the run,
+	// including handoffs
+	ToolCallCount int
+
 	// FinalOutput is the last assistant message content
 	FinalOutput string
+
+	// Refusal is the model's refusal text when the final reply declined to
+	// answer. FinalOutput holds it too, unless RunConfig.RefusalFallback is set.
+	Refusal string
+
+	// Truncated reports that the final output was cut off because the
+	// completion hit the token limit (finish_reason "length").
+	Truncated bool
+
+	// ResponseFormat is the format the final output was requested in. It is
+	// resolved from the final agent, so it reflects any handoff.
+	ResponseFormat *jsonschema.ResponseFormat
+
+	// ResponseID is the ID of the run's last chat completion. With
+	// RunConfig.StoreResponses it identifies the stored completion.
+	ResponseID string
+
+	// StopReason explains why the run ended. It is also set on the partial
+	// result returned alongside errors such as ErrMaxTurnsExceeded.
+	StopReason StopReason
+}
+
+// Into unmarshals the structured FinalOutput into v. When the response format
+// was created with jsonschema.JSONSchemaArray, the wrapping object is removed
+// so v can be a pointer to a slice. Decoding failures are returned as
+// *OutputValidationError.
+//
+// If the output was cut off by the token limit (see Truncated), Into repairs
+// the JSON as far as possible, decodes what it can into v and returns a
+// *TruncatedOutputError, so callers can still use the partial value.
+func (r *Result) Into(v any) error {
+	err := r.decode(r.FinalOutput, v)
+	if err == nil || !r.Truncated {
+		return err
+	}
+
+	repaired, ok := repairTruncatedJSON(r.FinalOutput)
+	return &TruncatedOutputError{
+		Output:    r.FinalOutput,
+		Recovered: ok && r.decode(repaired, v) == nil,
+		Err:       err,
+	}
 }
 
-// Usage tracks token consumption and costs
+// Unmarshal is a strict Into: before decoding, it checks FinalOutput
+// against the schema of the response format, so output that parses but
+// breaks the schema, such as a missing required field or an out-of-range
+// number, is reported rather than decoded into zero values. Failures are
+// returned as *OutputValidationError, wrapping a *SchemaViolationError for
+// schema mismatches. Truncated output is not repaired; use Into for that.
+func (r *Result) Unmarshal(v any) error {
+	if rf := r.ResponseFormat; rf != nil && rf.JSONSchema != nil && rf.JSONSchema.Schema != nil {
+		violations, err := rf.JSONSchema.Schema.CheckJSON([]byte(r.FinalOutput))
+		if err != nil {
+			return newOutputDecodeError("JSON", r.FinalOutput, err)
+		}
+		if len(violations) > 0 {
+			return newOutputDecodeError("output matching schema "+rf.JSONSchema.Name, r.FinalOutput,
+				&SchemaViolationError{Violations: violations})
+		}
+	}
+	return r.decode(r.FinalOutput, v)
+}
+
+// decode unmarshals output into v, unwrapping array response formats.
+func (r *Result) decode(output string, v any) error {
+	data := []byte(output)
+
+	if rf := r.ResponseFormat; rf != nil && rf.JSONSchema != nil && rf.JSONSchema.ArrayWrapped {
+		var wrapper map[string]json.RawMessage
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return newOutputDecodeError("JSON object", output, err)
+		}
+		items, ok := wrapper[jsonschema.ArrayWrapperKey]
+		if !ok {
+			return newOutputDecodeError("object with "+jsonschema.ArrayWrapperKey+" array", output,
+				fmt.Errorf("missing %q property", jsonschema.ArrayWrapperKey))
+		}
+		data = items
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return newOutputDecodeError(fmt.Sprintf("JSON decodable into %T", v), output, err)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the result in a stable form suitable for logging and
+// persistence. The agent is represented by its name, and step and tool call
+// durations are encoded in milliseconds.
+func (r Result) MarshalJSON() ([]byte, error) {
+	var agentName string
+	if r.Agent != nil {
+		agentName = r.Agent.Name
+	}
+	return json.Marshal(struct {
+		RunID          string                                   `json:"run_id"`
+		ResponseID     string                                   `json:"response_id,omitempty"`
+		Agent          string                                   `json:"agent"`
+		StopReason     StopReason                               `json:"stop_reason"`
+		FinalOutput    string                                   `json:"final_output"`
+		Refusal        string                                   `json:"refusal,omitempty"`
+		Truncated      bool                                     `json:"truncated,omitempty"`
+		ResponseFormat *jsonschema.ResponseFormat               `json:"response_format,omitempty"`
+		Usage          Usage                                    `json:"usage"`
+		Steps          []Step                                   `json:"steps"`
+		ToolCallCount  int                                      `json:"tool_call_count"`
+		Messages       []openai.ChatCompletionMessageParamUnion `json:"messages"`
+		Turns          []Turn                                   `json:"turns,omitempty"`
+	}{
+		RunID:          r.RunID,
+		ResponseID:     r.ResponseID,
+		Agent:          agentName,
+		StopReason:     r.StopReason,
+		FinalOutput:    r.FinalOutput,
+		Refusal:        r.Refusal,
+		Truncated:      r.Truncated,
+		ResponseFormat: r.ResponseFormat,
+		Usage:          r.Usage,
+		Steps:          r.Steps,
+		ToolCallCount:  r.ToolCallCount,
+		Messages:       r.Messages,
+		Turns:          r.Turns,
+	})
+}
+
+// StopReason describes why an agent run ended.
+type StopReason string
+
+const (
+	// StopReasonCompleted means the model replied without requesting tool calls
+	StopReasonCompleted StopReason = "completed"
+
+	// StopReasonMaxTurns means the run reached RunConfig.MaxTurns
+	StopReasonMaxTurns StopReason = "max_turns"
+
+	// StopReasonTokenBudget means the run exceeded RunConfig.MaxTotalTokens
+	StopReasonTokenBudget StopReason = "token_budget"
+
+	// StopReasonMaxToolCalls means the run reached RunConfig.MaxToolCalls
+	StopReasonMaxToolCalls StopReason = "max_tool_calls"
+
+	// StopReasonUnexpectedToolCall means the model called a tool on an agent without tools
+	StopReasonUnexpectedToolCall StopReason = "unexpected_tool_call"
+
+	// StopReasonGuardrail means a ConversationGuardrail rejected the conversation
+	// or an OutputGuardrail rejected the final output
+	StopReasonGuardrail StopReason = "guardrail"
+
+	// StopReasonTimeout means the run exceeded its deadline
+	StopReasonTimeout StopReason = "timeout"
+
+	// StopReasonCancelled means the run context was cancelled
+	StopReasonCancelled StopReason = "cancelled"
+)
+
+// Usage tracks token consumption. Cost estimates its price.
 type Usage struct {
 	// PromptTokens used across all LLM calls
-	PromptTokens int
+	PromptTokens int `json:"prompt_tokens"`
 
 	// CompletionTokens generated across all LLM calls
-	CompletionTokens int
+	CompletionTokens int `json:"completion_tokens"`
 
 	// TotalTokens = PromptTokens + CompletionTokens
-	TotalTokens int
+	TotalTokens int `json:"total_tokens"`
+
+	// AcceptedPredictionTokens are predicted output tokens that appeared in the completion
+	AcceptedPredictionTokens int `json:"accepted_prediction_tokens,omitempty"`
+
+	// RejectedPredictionTokens are predicted output tokens that did not appear
+	// in the completion; they are still billed as completion tokens
+	RejectedPredictionTokens int `json:"rejected_prediction_tokens,omitempty"`
 }
 
 // Add combines usage from multiple calls
@@ -41,6 +218,14 @@ func (u *Usage) Add(other Usage) {
 	u.PromptTokens += other.PromptTokens
 	u.CompletionTokens += other.CompletionTokens
 	u.TotalTokens += other.TotalTokens
+	u.AcceptedPredictionTokens += other.AcceptedPredictionTokens
+	u.RejectedPredictionTokens += other.RejectedPredictionTokens
+}
+
+// Reset zeroes all counters, e.g. to start a new accounting period
+// on a user-maintained running total.
+func (u *Usage) Reset() {
+	*u = Usage{}
 }
 
 // Step represents one iteration of the agent loop
@@ -56,10 +241,38 @@ type Step struct {
 
 	// StepNumber in the execution sequence
 	StepNumber int
+
+	// Usage is the token consumption of this step's LLM call
+	Usage Usage
+
+	// ResponseID is the ID of this step's chat completion
+	ResponseID string
+}
+
+// MarshalJSON encodes the step with its duration in milliseconds.
+func (s Step) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		StepNumber int        `json:"step_number"`
+		AgentName  string     `json:"agent_name"`
+		ResponseID string     `json:"response_id,omitempty"`
+		DurationMs int64      `json:"duration_ms"`
+		Usage      Usage      `json:"usage"`
+		ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	}{
+		StepNumber: s.StepNumber,
+		AgentName:  s.AgentName,
+		ResponseID: s.ResponseID,
+		DurationMs: s.Duration.Milliseconds(),
+		Usage:      s.Usage,
+		ToolCalls:  s.ToolCalls,
+	})
 }
 
 // ToolCall represents a tool execution
 type ToolCall struct {
+	// ID of the tool call, as it appears in Result.Messages
+	ID string
+
 	// ToolName that was called
 	ToolName string
 
@@ -72,8 +285,70 @@ type ToolCall struct {
 	// Error if tool execution failed
 	Error error
 
-	// Duration of tool execution
+	// Duration of tool execution, including retries
 	Duration time.Duration
+
+	// Attempts is how many times the callback ran; greater than 1 when
+	// the tool was retried, 0 when the tool was not found or the result
+	// was cached
+	Attempts int
+
+	// Cached reports that Result was reused from an earlier call with the
+	// same arguments, see Tool.Cacheable
+	Cached bool
+}
+
+// MarshalJSON encodes the tool call for logging. Arguments and results that
+// are valid JSON are embedded as-is, other results fall back to their %v
+// string form, the error is encoded as its message and the duration in
+// milliseconds.
+func (tc ToolCall) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if tc.Error != nil {
+		errMsg = tc.Error.Error()
+	}
+	return json.Marshal(struct {
+		ID         string          `json:"id,omitempty"`
+		ToolName   string          `json:"tool_name"`
+		Arguments  json.RawMessage `json:"arguments"`
+		Result     json.RawMessage `json:"result,omitempty"`
+		Error      string          `json:"error,omitempty"`
+		DurationMs int64           `json:"duration_ms"`
+		Attempts   int             `json:"attempts,omitempty"`
+		Cached     bool            `json:"cached,omitempty"`
+	}{
+		ID:         tc.ID,
+		ToolName:   tc.ToolName,
+		Arguments:  rawJSONOrString(tc.Arguments),
+		Result:     marshalToolResult(tc.Result),
+		Error:      errMsg,
+		DurationMs: tc.Duration.Milliseconds(),
+		Attempts:   tc.Attempts,
+		Cached:     tc.Cached,
+	})
+}
+
+// rawJSONOrString returns s unchanged if it is valid JSON and as a JSON
+// string otherwise.
+func rawJSONOrString(s string) json.RawMessage {
+	if s != "" && json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	data, _ := json.Marshal(s)
+	return data
+}
+
+// marshalToolResult encodes a tool result, falling back to its %v string
+// form when it cannot be marshaled.
+func marshalToolResult(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return data
+	}
+	data, _ := json.Marshal(fmt.Sprintf("%v", v))
+	return data
 }
 
 // ContextVariables is a map of variables that can be passed to functions.
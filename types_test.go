@@ -1,8 +1,15 @@
 package agents
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
+
+	"github.com/openai/openai-go"
+
+	"github.com/MitulShah1/openai-agents-go/internal/jsonschema"
 )
 
 func TestUsageAdd(t *testing.T) {
@@ -33,6 +40,28 @@ func TestUsageAdd(t *testing.T) {
 	}
 }
 
+func TestUsageAddPredictionTokens(t *testing.T) {
+	usage := Usage{AcceptedPredictionTokens: 10, RejectedPredictionTokens: 2}
+	usage.Add(Usage{AcceptedPredictionTokens: 5, RejectedPredictionTokens: 3})
+
+	if usage.AcceptedPredictionTokens != 15 {
+		t.Errorf("expected AcceptedPredictionTokens=15, got %d", usage.AcceptedPredictionTokens)
+	}
+
+	if usage.RejectedPredictionTokens != 5 {
+		t.Errorf("expected RejectedPredictionTokens=5, got %d", usage.RejectedPredictionTokens)
+	}
+}
+
+func TestUsageReset(t *testing.T) {
+	usage := Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3, AcceptedPredictionTokens: 4}
+	usage.Reset()
+
+	if usage != (Usage{}) {
+		t.Errorf("expected zero usage after Reset, got %+v", usage)
+	}
+}
+
 const testAgentName = "TestAgent"
 
 func TestStepCreation(t *testing.T) {
@@ -107,3 +136,263 @@ func TestContextVariables(t *testing.T) {
 		t.Error("failed to add new key")
 	}
 }
+
+func TestResultInto(t *testing.T) {
+	type person struct {
+		Name string `json:"name"`
+	}
+
+	t.Run("object output", func(t *testing.T) {
+		result := &Result{FinalOutput: `{"name":"Ada"}`}
+
+		var p person
+		if err := result.Into(&p); err != nil {
+			t.Fatalf("Into failed: %v", err)
+		}
+		if p.Name != "Ada" {
+			t.Errorf("expected Ada, got %q", p.Name)
+		}
+	})
+
+	t.Run("wrapped array output", func(t *testing.T) {
+		result := &Result{
+			FinalOutput:    `{"items":[{"name":"Ada"},{"name":"Grace"}]}`,
+			ResponseFormat: jsonschema.JSONSchemaArray("people", jsonschema.Object()),
+		}
+
+		var people []person
+		if err := result.Into(&people); err != nil {
+			t.Fatalf("Into failed: %v", err)
+		}
+		if len(people) != 2 || people[1].Name != "Grace" {
+			t.Errorf("unexpected people: %+v", people)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		result := &Result{FinalOutput: `not json`}
+
+		var p person
+		err := result.Into(&p)
+		var validationErr *OutputValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected OutputValidationError, got %v", err)
+		}
+	})
+
+	t.Run("wrapped output missing items", func(t *testing.T) {
+		result := &Result{
+			FinalOutput:    `{"people":[]}`,
+			ResponseFormat: jsonschema.JSONSchemaArray("people", jsonschema.Object()),
+		}
+
+		var people []person
+		if err := result.Into(&people); err == nil {
+			t.Error("expected error for missing items property")
+		}
+	})
+
+	t.Run("truncated output recovered", func(t *testing.T) {
+		result := &Result{
+			FinalOutput:    `{"items":[{"name":"Ada"},{"name":"Gra`,
+			ResponseFormat: jsonschema.JSONSchemaArray("people", jsonschema.Object()),
+			Truncated:      true,
+		}
+
+		var people []person
+		err := result.Into(&people)
+		var truncErr *TruncatedOutputError
+		if !errors.As(err, &truncErr) {
+			t.Fatalf("expected TruncatedOutputError, got %v", err)
+		}
+		if !truncErr.Recovered {
+			t.Fatal("expected partial value to be recovered")
+		}
+		if len(people) != 2 || people[0].Name != "Ada" || people[1].Name != "Gra" {
+			t.Errorf("unexpected partial people: %+v", people)
+		}
+	})
+
+	t.Run("truncated output unrecoverable", func(t *testing.T) {
+		result := &Result{FinalOutput: `Sorry, I`, Truncated: true}
+
+		var p person
+		err := result.Into(&p)
+		var truncErr *TruncatedOutputError
+		if !errors.As(err, &truncErr) || truncErr.Recovered {
+			t.Fatalf("expected unrecovered TruncatedOutputError, got %v", err)
+		}
+		if truncErr.Output != "Sorry, I" {
+			t.Errorf("expected raw output to be kept, got %q", truncErr.Output)
+		}
+	})
+}
+
+func TestResultUnmarshal(t *testing.T) {
+	type answer struct {
+		Answer     string  `json:"answer"`
+		Confidence float64 `json:"confidence"`
+	}
+	format := jsonschema.JSONSchema("answer", jsonschema.Object().
+		WithProperty("answer", jsonschema.String()).
+		WithProperty("confidence", jsonschema.Number().WithMaximum(1)).
+		WithRequired("answer", "confidence"))
+
+	tests := []struct {
+		name       string
+		output     string
+		format     *jsonschema.ResponseFormat
+		want       answer
+		violations int
+		wantErr    bool
+	}{
+		{name: "valid", output: `{"answer":"42","confidence":0.9}`, format: format, want: answer{"42", 0.9}},
+		{name: "no schema", output: `{"answer":"42"}`, want: answer{Answer: "42"}},
+		{name: "missing required", output: `{"answer":"42"}`, format: format, violations: 1, wantErr: true},
+		{name: "out of range", output: `{"answer":"42","confidence":7}`, format: format, violations: 1, wantErr: true},
+		{name: "invalid JSON", output: `not json`, format: format, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &Result{FinalOutput: tt.output, ResponseFormat: tt.format}
+
+			var got answer
+			err := result.Unmarshal(&got)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Unmarshal failed: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("expected %+v, got %+v", tt.want, got)
+				}
+				return
+			}
+
+			var validationErr *OutputValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected OutputValidationError, got %v", err)
+			}
+			var schemaErr *SchemaViolationError
+			if errors.As(err, &schemaErr) != (tt.violations > 0) {
+				t.Fatalf("unexpected schema error state: %v", err)
+			}
+			if schemaErr != nil && len(schemaErr.Violations) != tt.violations {
+				t.Errorf("expected %d violations, got %v", tt.violations, schemaErr.Violations)
+			}
+			if got != (answer{}) {
+				t.Errorf("expected nothing to be decoded, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestToolCallMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		call ToolCall
+		want string
+	}{
+		{
+			name: "json result",
+			call: ToolCall{ToolName: "add", Arguments: `{"a":1}`, Result: map[string]int{"sum": 3}, Duration: 1500 * time.Microsecond},
+			want: `{"tool_name":"add","arguments":{"a":1},"result":{"sum":3},"duration_ms":1}`,
+		},
+		{
+			name: "error as string",
+			call: ToolCall{ToolName: "fail", Arguments: `{}`, Error: errors.New("boom")},
+			want: `{"tool_name":"fail","arguments":{},"error":"boom","duration_ms":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.call)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got  %s\nwant %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolCallMarshalJSON_Fallbacks(t *testing.T) {
+	ch := make(chan int)
+	data, err := json.Marshal(ToolCall{ToolName: "odd", Arguments: "not json", Result: ch})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	if got["arguments"] != "not json" {
+		t.Errorf("expected arguments as string, got %v", got["arguments"])
+	}
+	if got["result"] != fmt.Sprintf("%v", ch) {
+		t.Errorf("expected %%v fallback for result, got %v", got["result"])
+	}
+}
+
+func TestResultMarshalJSON(t *testing.T) {
+	result := &Result{
+		RunID:       "run_1",
+		Agent:       NewAgent("Assistant"),
+		FinalOutput: "done",
+		StopReason:  StopReasonCompleted,
+		Usage:       Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		Steps: []Step{{
+			StepNumber: 1,
+			AgentName:  "Assistant",
+			Duration:   2 * time.Second,
+			ToolCalls:  []ToolCall{{ToolName: "fail", Arguments: `{}`, Error: errors.New("boom")}},
+		}},
+		ToolCallCount: 1,
+		Messages:      []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")},
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var got struct {
+		RunID      string `json:"run_id"`
+		Agent      string `json:"agent"`
+		StopReason string `json:"stop_reason"`
+		Usage      Usage  `json:"usage"`
+		Steps      []struct {
+			DurationMs int64 `json:"duration_ms"`
+			ToolCalls  []struct {
+				Error string `json:"error"`
+			} `json:"tool_calls"`
+		} `json:"steps"`
+		ToolCallCount *int             `json:"tool_call_count"`
+		Messages      []map[string]any `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if got.RunID != "run_1" || got.Agent != "Assistant" || got.StopReason != "completed" {
+		t.Errorf("unexpected header fields: %s", data)
+	}
+	if got.Usage.TotalTokens != 15 {
+		t.Errorf("expected total tokens 15, got %d", got.Usage.TotalTokens)
+	}
+	if len(got.Steps) != 1 || got.Steps[0].DurationMs != 2000 {
+		t.Fatalf("expected one step of 2000ms, got %s", data)
+	}
+	if got.ToolCallCount == nil || *got.ToolCallCount != 1 {
+		t.Errorf("expected tool_call_count 1, got %s", data)
+	}
+	if got.Steps[0].ToolCalls[0].Error != "boom" {
+		t.Errorf("expected tool error message, got %q", got.Steps[0].ToolCalls[0].Error)
+	}
+	if len(got.Messages) != 1 || got.Messages[0]["role"] != "user" {
+		t.Errorf("expected user message, got %v", got.Messages)
+	}
+}